@@ -16,21 +16,74 @@
 package client
 
 import (
+	"context"
+	"crypto/x509"
 	"strings"
 	"terraform-provider-solacebroker/cmd/generator"
 	"terraform-provider-solacebroker/internal/broker"
 	"terraform-provider-solacebroker/internal/semp"
+	"unicode"
 )
 
 func CliClient(cliParams generator.CliParams) *semp.Client {
-	client := semp.NewClient(
-		getFullSempAPIURL(*cliParams.Url),
-		*cliParams.Insecure_skip_verify,
-		false, // this is a client for the generator
+	var options []semp.Option
+	if *cliParams.Client_certificate != "" {
+		certificate, err := semp.LoadClientCertificate(*cliParams.Client_certificate, *cliParams.Client_private_key, *cliParams.Client_private_key_password)
+		if err != nil {
+			generator.ExitWithError("Unable to load client certificate, " + err.Error())
+		}
+		options = append(options, semp.ClientCertificate(certificate))
+	}
+	if *cliParams.Oauth_token_url != "" {
+		scopes := strings.FieldsFunc(*cliParams.Oauth_scopes, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		options = append(options, semp.OAuthClientCredentials(*cliParams.Oauth_token_url, *cliParams.Oauth_client_id, *cliParams.Oauth_client_secret, scopes))
+	}
+	if *cliParams.Session_login {
+		options = append(options, semp.SessionLogin())
+	}
+	var rootCAs *x509.CertPool
+	if *cliParams.Ca_certificate != "" {
+		var err error
+		rootCAs, err = semp.LoadCACertificates(*cliParams.Ca_certificate)
+		if err != nil {
+			generator.ExitWithError("Unable to load CA certificate, " + err.Error())
+		}
+	}
+	if *cliParams.Proxy_url != "" || *cliParams.No_proxy != "" {
+		options = append(options, semp.Proxy(*cliParams.Proxy_url, *cliParams.No_proxy))
+	}
+	// already validated in UpdateCliParamsWithEnv
+	requestHeaders, _ := semp.ParseRequestHeaders(*cliParams.Request_headers)
+	if len(requestHeaders) > 0 || *cliParams.Host_header != "" {
+		options = append(options, semp.RequestHeaders(requestHeaders, *cliParams.Host_header))
+	}
+	// already validated in UpdateCliParamsWithEnv
+	redactPatterns, _ := semp.ParseRedactPatterns(generator.RedactPatterns(*cliParams.Redact_patterns))
+	urls := getFullSempAPIURLs(*cliParams.Url)
+	// already validated in UpdateCliParamsWithEnv
+	tlsMinVersion, _ := semp.ParseTLSVersion(*cliParams.Tls_min_version)
+	options = append(options,
 		semp.BasicAuth(*cliParams.Username, *cliParams.Password),
 		semp.BearerToken(*cliParams.Bearer_token),
+		semp.TLSSettings(rootCAs, *cliParams.Tls_server_name, tlsMinVersion),
 		semp.Retries(*cliParams.Retries, *cliParams.Retry_min_interval, *cliParams.Retry_max_interval),
-		semp.RequestLimits(*cliParams.Request_timeout_duration, *cliParams.Request_min_interval))
+		semp.RequestLimits(*cliParams.Request_timeout_duration, *cliParams.Request_min_interval),
+		semp.RequestBurst(*cliParams.Request_burst_size),
+		semp.MaxConcurrentRequests(*cliParams.Max_concurrent_requests),
+		semp.FailoverURLs(urls[1:]...),
+		semp.PathTemplates(broker.PathTemplates()...),
+		semp.SensitiveAttributes(broker.SensitiveAttributeNames()...),
+		semp.RedactPatterns(redactPatterns...),
+		// the generator only reads the broker configuration
+		semp.ReadOnly(true))
+	client := semp.NewClient(
+		urls[0],
+		*cliParams.Insecure_skip_verify,
+		false, // this is a client for the generator
+		options...)
+	client.SelectActiveNode(context.Background())
 	return client
 }
 
@@ -39,3 +92,17 @@ func getFullSempAPIURL(url string) string {
 	baseBath := strings.TrimPrefix(broker.SempDetail.BasePath, "/")
 	return url + "/" + baseBath
 }
+
+// getFullSempAPIURLs returns the SEMP API URLs of the comma-separated broker URLs of a redundancy group
+func getFullSempAPIURLs(url string) []string {
+	var urls []string
+	for _, nodeURL := range strings.Split(url, ",") {
+		if nodeURL = strings.TrimSpace(nodeURL); nodeURL != "" {
+			urls = append(urls, getFullSempAPIURL(nodeURL))
+		}
+	}
+	if len(urls) == 0 {
+		urls = append(urls, getFullSempAPIURL(url))
+	}
+	return urls
+}
@@ -22,10 +22,12 @@ import (
 	"strings"
 	"terraform-provider-solacebroker/cmd/client"
 	"terraform-provider-solacebroker/cmd/generator"
-	"terraform-provider-solacebroker/internal/broker/generated"
+	"terraform-provider-solacebroker/internal/broker"
+	_ "terraform-provider-solacebroker/internal/broker/generated"
 	"terraform-provider-solacebroker/internal/semp"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
 )
 
 // generateCmd represents the generate command
@@ -75,11 +77,66 @@ The message VPN resource address in the generated configuration will be 'solaceb
 				cliParams.Password = &password
 			}
 		}
+		if flags.Changed("password_file") {
+			if passwordFile, err := flags.GetString("password_file"); err == nil {
+				cliParams.Password_file = &passwordFile
+			}
+		}
+		if flags.Changed("bearer_token_file") {
+			if bearerTokenFile, err := flags.GetString("bearer_token_file"); err == nil {
+				cliParams.Bearer_token_file = &bearerTokenFile
+			}
+		}
+		if flags.Changed("credential_process") {
+			if credentialProcess, err := flags.GetString("credential_process"); err == nil {
+				cliParams.Credential_process = &credentialProcess
+			}
+		}
 		if flags.Changed("bearer_token") {
 			if bearerToken, err := flags.GetString("bearer_token"); err == nil {
 				cliParams.Bearer_token = &bearerToken
 			}
 		}
+		if flags.Changed("client_certificate") {
+			if clientCertificate, err := flags.GetString("client_certificate"); err == nil {
+				cliParams.Client_certificate = &clientCertificate
+			}
+		}
+		if flags.Changed("client_private_key") {
+			if clientPrivateKey, err := flags.GetString("client_private_key"); err == nil {
+				cliParams.Client_private_key = &clientPrivateKey
+			}
+		}
+		if flags.Changed("client_private_key_password") {
+			if clientPrivateKeyPassword, err := flags.GetString("client_private_key_password"); err == nil {
+				cliParams.Client_private_key_password = &clientPrivateKeyPassword
+			}
+		}
+		if flags.Changed("oauth_token_url") {
+			if oauthTokenURL, err := flags.GetString("oauth_token_url"); err == nil {
+				cliParams.Oauth_token_url = &oauthTokenURL
+			}
+		}
+		if flags.Changed("oauth_client_id") {
+			if oauthClientID, err := flags.GetString("oauth_client_id"); err == nil {
+				cliParams.Oauth_client_id = &oauthClientID
+			}
+		}
+		if flags.Changed("oauth_client_secret") {
+			if oauthClientSecret, err := flags.GetString("oauth_client_secret"); err == nil {
+				cliParams.Oauth_client_secret = &oauthClientSecret
+			}
+		}
+		if flags.Changed("oauth_scopes") {
+			if oauthScopes, err := flags.GetString("oauth_scopes"); err == nil {
+				cliParams.Oauth_scopes = &oauthScopes
+			}
+		}
+		if flags.Changed("session_login") {
+			if sessionLogin, err := flags.GetBool("session_login"); err == nil {
+				cliParams.Session_login = &sessionLogin
+			}
+		}
 		if flags.Changed("retries") {
 			if retries, err := flags.GetInt64("retries"); err == nil {
 				cliParams.Retries = &retries
@@ -105,11 +162,61 @@ The message VPN resource address in the generated configuration will be 'solaceb
 				cliParams.Request_min_interval = &requestMinInterval
 			}
 		}
+		if flags.Changed("request_burst_size") {
+			if requestBurstSize, err := flags.GetInt64("request_burst_size"); err == nil {
+				cliParams.Request_burst_size = &requestBurstSize
+			}
+		}
+		if flags.Changed("max_concurrent_requests") {
+			if maxConcurrentRequests, err := flags.GetInt64("max_concurrent_requests"); err == nil {
+				cliParams.Max_concurrent_requests = &maxConcurrentRequests
+			}
+		}
 		if flags.Changed("insecure_skip_verify") {
 			if insecureSkipVerify, err := flags.GetBool("insecure_skip_verify"); err == nil {
 				cliParams.Insecure_skip_verify = &insecureSkipVerify
 			}
 		}
+		if flags.Changed("ca_certificate") {
+			if caCertificate, err := flags.GetString("ca_certificate"); err == nil {
+				cliParams.Ca_certificate = &caCertificate
+			}
+		}
+		if flags.Changed("tls_server_name") {
+			if tlsServerName, err := flags.GetString("tls_server_name"); err == nil {
+				cliParams.Tls_server_name = &tlsServerName
+			}
+		}
+		if flags.Changed("tls_min_version") {
+			if tlsMinVersion, err := flags.GetString("tls_min_version"); err == nil {
+				cliParams.Tls_min_version = &tlsMinVersion
+			}
+		}
+		if flags.Changed("proxy_url") {
+			if proxyURL, err := flags.GetString("proxy_url"); err == nil {
+				cliParams.Proxy_url = &proxyURL
+			}
+		}
+		if flags.Changed("no_proxy") {
+			if noProxy, err := flags.GetString("no_proxy"); err == nil {
+				cliParams.No_proxy = &noProxy
+			}
+		}
+		if flags.Changed("request_headers") {
+			if requestHeaders, err := flags.GetString("request_headers"); err == nil {
+				cliParams.Request_headers = &requestHeaders
+			}
+		}
+		if flags.Changed("host_header") {
+			if hostHeader, err := flags.GetString("host_header"); err == nil {
+				cliParams.Host_header = &hostHeader
+			}
+		}
+		if flags.Changed("redact_patterns") {
+			if redactPatterns, err := flags.GetString("redact_patterns"); err == nil {
+				cliParams.Redact_patterns = &redactPatterns
+			}
+		}
 		if flags.Changed("skip_api_check") {
 			if skipApiCheck, err := flags.GetBool("skip_api_check"); err == nil {
 				cliParams.Skip_api_check = &skipApiCheck
@@ -122,6 +229,11 @@ The message VPN resource address in the generated configuration will be 'solaceb
 		if cliClient == nil {
 			generator.ExitWithError("Error creating SEMP Client")
 		}
+		stopTracing, err := semp.StartTracing(cmd.Context(), "terraform-provider-solacebroker-generator", broker.ProviderVersion)
+		if err != nil {
+			generator.ExitWithError("Unable to start tracing, " + err.Error())
+		}
+		ctx, span := otel.Tracer(semp.TracerName).Start(cmd.Context(), "generate")
 
 		brokerObjectType := flags.Arg(0)
 
@@ -151,17 +263,30 @@ The message VPN resource address in the generated configuration will be 'solaceb
 		skipApiCheck := *cliParams.Skip_api_check
 		//Confirm SEMP version and connection via client
 		aboutPath := "/about/api"
-		result, err := cliClient.RequestWithoutBody(cmd.Context(), http.MethodGet, aboutPath)
+		result, err := cliClient.RequestWithoutBody(ctx, http.MethodGet, aboutPath)
 		if err != nil {
 			generator.ExitWithError("SEMP call failed. " + err.Error())
 		}
 		brokerSempVersion := result["sempVersion"].(string)
 		brokerPlatform := result["platform"].(string)
-		if !skipApiCheck && brokerPlatform != generated.Platform {
-			generator.ExitWithError(fmt.Sprintf("Broker platform \"%s\" does not match generator supported platform: %s", BrokerPlatformName[brokerPlatform], BrokerPlatformName[generated.Platform]))
+		if _, ok := broker.SempDetails[brokerPlatform]; !ok {
+			if !skipApiCheck {
+				var supported []string
+				for _, platform := range broker.SupportedPlatforms() {
+					supported = append(supported, BrokerPlatformName[platform])
+				}
+				generator.ExitWithError(fmt.Sprintf("Broker platform \"%s\" does not match generator supported platform: %s", BrokerPlatformName[brokerPlatform], strings.Join(supported, ", ")))
+			}
+			brokerPlatform = broker.SempDetail.Platform
 		}
+		// generate the object types of the SEMP spec of the broker platform
+		generator.Platform = brokerPlatform
+		generatorSempVersion := broker.SempDetails[brokerPlatform].SempVersion
 		generator.LogCLIInfo("Connection successful.")
-		generator.LogCLIInfo(fmt.Sprintf("Broker SEMP version is %s, Generator SEMP version is %s", brokerSempVersion, generated.SempVersion))
+		generator.LogCLIInfo(fmt.Sprintf("Broker SEMP version is %s, Generator SEMP version is %s", brokerSempVersion, generatorSempVersion))
+		for _, line := range generator.CompatibilityReport(ctx, cliClient, brokerPlatform, brokerSempVersion, generatorSempVersion) {
+			generator.LogCLIInfo(line)
+		}
 
 		generator.LogCLIInfo(fmt.Sprintf("Attempting config generation for object and its child-objects: %s, identifier: %s, destination file: %s\n", brokerObjectType, providerSpecificIdentifier, fileName))
 
@@ -176,7 +301,15 @@ The message VPN resource address in the generated configuration will be 'solaceb
 		}
 
 		brokerResourceTerraformName := strings.ReplaceAll(brokerResourceType, "solacebroker_", "")
-		generator.GenerateAll(cliParams, cmd.Context(), cliClient, brokerResourceTerraformName, brokerResourceName, providerSpecificIdentifier, fileName)
+		generator.GenerateAll(cliParams, ctx, cliClient, brokerResourceTerraformName, brokerResourceName, providerSpecificIdentifier, fileName)
+		cliClient.Logout(ctx)
+		span.End()
+		stopTracing(cmd.Context())
+		stats := cliClient.Stats()
+		generator.LogCLIInfo("SEMP request statistics:\n" + semp.FormatStats(stats))
+		if err := semp.WriteStatsFile(stats); err != nil {
+			generator.LogCLIError(err.Error())
+		}
 
 		os.Exit(0)
 	},
@@ -187,12 +320,33 @@ func init() {
 	generateCmd.PersistentFlags().String("url", "http://localhost:8080", "Broker base URL, for example https://mybroker.example.org:<semp-service-port>")
 	generateCmd.PersistentFlags().String("username", "", "Basic authentication username")
 	generateCmd.PersistentFlags().String("password", "", "Basic authentication password")
+	generateCmd.PersistentFlags().String("password_file", "", "File holding the basic authentication password")
 	generateCmd.PersistentFlags().String("bearer_token", "", "Bearer token for authentication")
+	generateCmd.PersistentFlags().String("bearer_token_file", "", "File holding the bearer token for authentication")
+	generateCmd.PersistentFlags().String("credential_process", "", "Command printing the credentials as JSON with username and password or bearer_token")
+	generateCmd.PersistentFlags().String("client_certificate", "", "Client certificate for authentication, PEM content or file path")
+	generateCmd.PersistentFlags().String("client_private_key", "", "Client certificate private key, PEM content or file path")
+	generateCmd.PersistentFlags().String("client_private_key_password", "", "Password of an encrypted client certificate private key")
+	generateCmd.PersistentFlags().String("oauth_token_url", "", "OAuth token endpoint to obtain access tokens from using the client credentials grant")
+	generateCmd.PersistentFlags().String("oauth_client_id", "", "OAuth client ID")
+	generateCmd.PersistentFlags().String("oauth_client_secret", "", "OAuth client secret")
+	generateCmd.PersistentFlags().String("oauth_scopes", "", "Comma-separated OAuth scopes to request")
+	generateCmd.PersistentFlags().Bool("session_login", false, "Log in once and authenticate later requests with the broker session cookie")
 	generateCmd.PersistentFlags().Int64("retries", semp.DefaultRetries, "Retries")
 	generateCmd.PersistentFlags().Duration("retry_min_interval", semp.DefaultRetryMinInterval, "Minimum retry interval")
 	generateCmd.PersistentFlags().Duration("retry_max_interval", semp.DefaultRetryMaxInterval, "Maximum retry interval")
 	generateCmd.PersistentFlags().Duration("request_timeout_duration", semp.DefaultRequestTimeout, "Request timeout duration")
 	generateCmd.PersistentFlags().Duration("request_min_interval", semp.DefaultRequestInterval, "Minimum request interval")
+	generateCmd.PersistentFlags().Int64("request_burst_size", semp.DefaultRequestBurst, "Number of requests that may be sent in a row before the minimum request interval applies")
+	generateCmd.PersistentFlags().Int64("max_concurrent_requests", semp.DefaultMaxConcurrent, "Maximum number of concurrent requests, also sizes the connection pool")
 	generateCmd.PersistentFlags().Bool("insecure_skip_verify", false, "Disable validation of server SSL certificates")
+	generateCmd.PersistentFlags().String("ca_certificate", "", "Trusted CA certificate bundle to validate the broker server certificate, PEM content or file path")
+	generateCmd.PersistentFlags().String("tls_server_name", "", "Server name to validate the broker server certificate with, if different from the host in url")
+	generateCmd.PersistentFlags().String("tls_min_version", "1.2", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	generateCmd.PersistentFlags().String("proxy_url", "", "Proxy to send requests through instead of the proxy from HTTP_PROXY and HTTPS_PROXY")
+	generateCmd.PersistentFlags().String("no_proxy", "", "Comma-separated hosts reached without a proxy, replaces NO_PROXY")
+	generateCmd.PersistentFlags().String("request_headers", "", "Comma-separated name=value headers to send with every request")
+	generateCmd.PersistentFlags().String("host_header", "", "Host header to send with every request, if different from the host in url")
+	generateCmd.PersistentFlags().String("redact_patterns", "", "Newline-separated regular expressions matching text to mask in error messages and recorded traffic")
 	generateCmd.PersistentFlags().Bool("skip_api_check", false, "Disable validation of the broker SEMP API")
 }
@@ -24,7 +24,7 @@ import (
 	"regexp"
 	"strings"
 	internalbroker "terraform-provider-solacebroker/internal/broker"
-	"terraform-provider-solacebroker/internal/broker/generated"
+	_ "terraform-provider-solacebroker/internal/broker/generated"
 	"terraform-provider-solacebroker/internal/semp"
 )
 
@@ -107,7 +107,7 @@ func getInstancePathTemplate(brokerObjectType BrokerObjectType) (string, error)
 	if !ok {
 		return "", fmt.Errorf("invalid broker object type")
 	}
-	dsEntity := internalbroker.Entities[i]
+	dsEntity := entities[i]
 	return dsEntity.PathTemplate, nil
 }
 
@@ -162,7 +162,7 @@ func identifierToBrokerObjectAttributes(brokerObjectType BrokerObjectType, ident
 // Returns one instance of the brokerObjectType if identifier has been provided, otherwise all instances that match the parentIdentifyingAttributes
 // Communicates with the broker via the SEMP client to fetch the instances
 // As a side effect, it will also construct an identifier for an object instance, prep the attributes and cache the results for later use
-func getInstances(context context.Context, client semp.Client, brokerObjectType BrokerObjectType, identifier string, parent BrokerObjectInstanceInfo) ([]BrokerObjectInstanceInfo, error) {
+func getInstances(context context.Context, client *semp.Client, brokerObjectType BrokerObjectType, identifier string, parent BrokerObjectInstanceInfo) ([]BrokerObjectInstanceInfo, error) {
 	var instances []BrokerObjectInstanceInfo
 
 	if identifier != "" {
@@ -181,7 +181,7 @@ func getInstances(context context.Context, client semp.Client, brokerObjectType
 		if err != nil {
 			return nil, err
 		}
-		results, err := client.RequestWithoutBodyForGenerator(context, generated.BasePath, http.MethodGet, requestPath, []map[string]any{})
+		results, err := client.RequestWithoutBodyForGenerator(context, internalbroker.PlatformSempDetail(Platform).BasePath, http.MethodGet, requestPath, []map[string]any{})
 		if err != nil {
 			return nil, err
 		}
@@ -190,7 +190,7 @@ func getInstances(context context.Context, client semp.Client, brokerObjectType
 			return nil, err
 		}
 		// create a resource config from results[0]
-		attributes := internalbroker.Entities[DSLookup[BrokerObjectType(brokerObjectType)]].Attributes
+		attributes := entities[DSLookup[BrokerObjectType(brokerObjectType)]].Attributes
 		resourceValues, tfVariables, err := processSempResults(resourceTypeAndName, attributes, results, BrokerObjectInstanceInfo{})
 		if err != nil {
 			return nil, err
@@ -216,7 +216,7 @@ func getInstances(context context.Context, client semp.Client, brokerObjectType
 		if err != nil {
 			return nil, err
 		}
-		results, err := client.RequestWithoutBodyForGenerator(context, generated.BasePath, http.MethodGet, requestPath, []map[string]any{})
+		results, err := client.RequestWithoutBodyForGenerator(context, internalbroker.PlatformSempDetail(Platform).BasePath, http.MethodGet, requestPath, []map[string]any{})
 		if err != nil {
 			// Fail except if the path is invalid - this means the generator SEMP schema is trying
 			// to fetch a resource that doesn't exist in an older broker
@@ -250,7 +250,7 @@ func getInstances(context context.Context, client semp.Client, brokerObjectType
 				// create a resource config from result
 				var elems []map[string]interface{}
 				elems = append(elems, result)
-				attributes := internalbroker.Entities[DSLookup[BrokerObjectType(brokerObjectType)]].Attributes
+				attributes := entities[DSLookup[BrokerObjectType(brokerObjectType)]].Attributes
 				resourceValues, tfVariables, err := processSempResults(resourceTypeAndName, attributes, elems, parent)
 				if err != nil {
 					return nil, err
@@ -273,7 +273,7 @@ func getInstances(context context.Context, client semp.Client, brokerObjectType
 }
 
 // Main entry point to generate the config for a broker object
-func fetchBrokerConfig(context context.Context, client semp.Client, brokerObjectType BrokerObjectType, brokerResourceName string, identifier string) ([]map[string]ResourceConfig, map[string]VariableConfig, error) {
+func fetchBrokerConfig(context context.Context, client *semp.Client, brokerObjectType BrokerObjectType, brokerResourceName string, identifier string) ([]map[string]ResourceConfig, map[string]VariableConfig, error) {
 	var err error
 	cachedResources = make(map[string]interface{})
 	variables = map[string]VariableConfig{}
@@ -292,7 +292,7 @@ func fetchBrokerConfig(context context.Context, client semp.Client, brokerObject
 // This is a recursive function that generates the config for a broker object and its children
 // The entry point is the parent object with the identifier. For child objects the identifier is empty
 // It will call itself for each child object instance
-func GenerateConfigForObjectInstances(context context.Context, client semp.Client, brokerObjectType BrokerObjectType, identifier string, parentInstanceInfo BrokerObjectInstanceInfo) error {
+func GenerateConfigForObjectInstances(context context.Context, client *semp.Client, brokerObjectType BrokerObjectType, identifier string, parentInstanceInfo BrokerObjectInstanceInfo) error {
 	// brokerObjectType is the current object type
 	// instances is the list of instances of the current object type
 	LogCLIInfo(fmt.Sprintf("  ## Fetching config for resource %s\n", brokerObjectType))
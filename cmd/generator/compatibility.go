@@ -0,0 +1,58 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package generator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"terraform-provider-solacebroker/internal/broker"
+	"terraform-provider-solacebroker/internal/semp"
+)
+
+// CompatibilityReport assesses the compatibility of the broker with the generator, comparing the broker SEMP version
+// with the SEMP version the generator is built for and listing the resource types the broker does not support
+func CompatibilityReport(ctx context.Context, client *semp.Client, platform string, brokerSempVersion string, generatorSempVersion string) []string {
+	var report []string
+	if brokerVersion, err := client.BrokerVersion(ctx); err != nil {
+		report = append(report, fmt.Sprintf("Broker version could not be read: %v", err))
+	} else {
+		report = append(report, fmt.Sprintf("Broker version is %s", brokerVersion))
+	}
+	brokerVersion, err := broker.ParseSempVersion(brokerSempVersion)
+	if err != nil {
+		return append(report, fmt.Sprintf("Unable to compare SEMP versions: %v", err))
+	}
+	generatorVersion, err := broker.ParseSempVersion(generatorSempVersion)
+	if err != nil {
+		return append(report, fmt.Sprintf("Unable to compare SEMP versions: %v", err))
+	}
+	switch {
+	case brokerVersion.LessThan(generatorVersion):
+		unsupported := broker.UnsupportedResources(platform, brokerVersion)
+		if len(unsupported) == 0 {
+			report = append(report, fmt.Sprintf("Broker SEMP version %s is older than the generator SEMP version %s, attributes added in later SEMP versions are not available", brokerSempVersion, generatorSempVersion))
+		} else {
+			report = append(report, fmt.Sprintf("Broker SEMP version %s is older than the generator SEMP version %s, the broker does not support the following resource types: %s", brokerSempVersion, generatorSempVersion, strings.Join(unsupported, ", ")))
+		}
+	case brokerVersion.GreaterThan(generatorVersion):
+		report = append(report, fmt.Sprintf("Broker SEMP version %s is newer than the generator SEMP version %s, objects and attributes added in later SEMP versions are not generated", brokerSempVersion, generatorSempVersion))
+	default:
+		report = append(report, "Broker and generator SEMP versions match")
+	}
+	return report
+}
@@ -0,0 +1,55 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"terraform-provider-solacebroker/internal/semp"
+)
+
+func TestCompatibilityReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<rpc-reply><rpc><show><version><current-load>soltr_10.5.0.12</current-load></version></show></rpc><execute-result code="ok"/></rpc-reply>`))
+	}))
+	defer server.Close()
+	client := semp.NewClient(server.URL+"/SEMP/v2/config", false, false, semp.BasicAuth("admin", "admin"), semp.Retries(0, 0, 0))
+	tests := []struct {
+		name              string
+		brokerSempVersion string
+		want              string
+	}{
+		{"Older", "2.38", "the broker does not support the following resource types: msg_vpn_authentication_kerberos_realm, proxy"},
+		{"Newer", "2.99", "is newer than the generator SEMP version 2.49"},
+		{"Same", "2.49", "Broker and generator SEMP versions match"},
+		{"Invalid", "not a version", "Unable to compare SEMP versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CompatibilityReport(context.Background(), client, "VMR", tt.brokerSempVersion, "2.49")
+			if len(report) != 2 || report[0] != "Broker version is 10.5.0.12" {
+				t.Fatalf("unexpected report %v", report)
+			}
+			if !strings.Contains(report[1], tt.want) {
+				t.Errorf("expected %q in %q", tt.want, report[1])
+			}
+		})
+	}
+}
@@ -42,14 +42,22 @@ type VariableConfig struct {
 }
 
 type ObjectInfo struct {
-	BasicAuthentication bool
-	FileName            string
-	BrokerResources     []map[string]string
-	Variables           map[string]VariableConfig
+	BasicAuthentication             bool
+	ClientCertificateAuthentication bool
+	OAuthAuthentication             bool
+	FileName                        string
+	BrokerResources                 []map[string]string
+	Variables                       map[string]VariableConfig
 }
 
 var BrokerObjectRelationship = map[BrokerObjectType][]BrokerObjectType{}
-var DSLookup = map[BrokerObjectType]int{} // Helper to easily lookup an entity in internalbroker.Entities by name
+var DSLookup = map[BrokerObjectType]int{} // Helper to easily lookup an entity in entities by name
+
+// Platform is the broker platform whose object types are generated, the default platform of the provider if empty
+var Platform string
+
+// entities are the object types of the generated platform, set by CreateBrokerObjectRelationships
+var entities []internalbroker.EntityInputs
 
 var ObjectNamesCount = map[string]int{}
 
@@ -64,7 +72,7 @@ func GenerateAll(cliParams CliParams, context context.Context, cliClient *semp.C
 	}
 
 	// This will iterate all resources starting at brokerResourceTerraformName and genarete brokerResources and variables config for that and children
-	brokerResources, variables, err := fetchBrokerConfig(context, *cliClient, BrokerObjectType(brokerResourceTerraformName), brokerResourceName, providerSpecificIdentifier)
+	brokerResources, variables, err := fetchBrokerConfig(context, cliClient, BrokerObjectType(brokerResourceTerraformName), brokerResourceName, providerSpecificIdentifier)
 	if err != nil {
 		ExitWithError("Failed to fetch broker config, " + err.Error())
 	}
@@ -78,6 +86,8 @@ func GenerateAll(cliParams CliParams, context context.Context, cliClient *semp.C
 	object.BrokerResources = resourcesToFormattedHCL(brokerResources)
 	object.Variables = variables
 	object.BasicAuthentication = (*cliParams.Username != "" && *cliParams.Bearer_token == "")
+	object.ClientCertificateAuthentication = (*cliParams.Client_certificate != "")
+	object.OAuthAuthentication = (*cliParams.Oauth_token_url != "")
 	object.FileName = fileName
 	LogCLIInfo("Found all resources. Writing file " + fileName)
 
@@ -92,7 +102,8 @@ func GenerateAll(cliParams CliParams, context context.Context, cliClient *semp.C
 func CreateBrokerObjectRelationships() {
 	// Loop through entities and build database
 	resourcesPathSignatureMap := map[string]string{}
-	e := internalbroker.Entities
+	entities = internalbroker.PlatformEntities(Platform)
+	e := entities
 	for i, ds := range e {
 		// Create new entry for each resource
 		BrokerObjectRelationship[BrokerObjectType(ds.TerraformName)] = []BrokerObjectType{}
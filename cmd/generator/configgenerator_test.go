@@ -16,7 +16,18 @@
 package generator
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+
+	internalbroker "terraform-provider-solacebroker/internal/broker"
+	"terraform-provider-solacebroker/internal/semp"
+	"terraform-provider-solacebroker/internal/semp/semptest"
 )
 
 func TestCreateBrokerObjectRelationships(t *testing.T) {
@@ -38,3 +49,48 @@ func TestCreateBrokerObjectRelationships(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateAll(t *testing.T) {
+	server := httptest.NewServer(semptest.New(semptest.BasicAuth("admin", "admin")))
+	defer server.Close()
+	client := semp.NewClient(server.URL+internalbroker.SempDetail.BasePath, false, false,
+		semp.BasicAuth("admin", "admin"), semp.Retries(0, 0, 0), semp.RequestLimits(0, 0))
+	ctx := context.Background()
+	for _, object := range []struct {
+		path string
+		body map[string]any
+	}{
+		{"/msgVpns/test", map[string]any{"msgVpnName": "test", "enabled": true}},
+		{"/msgVpns/test/queues/orders", map[string]any{"queueName": "orders", "maxMsgSize": 1000}},
+		{"/msgVpns/test/clientUsernames/app", map[string]any{"clientUsername": "app", "password": "app-secret"}},
+	} {
+		if _, err := client.RequestWithBody(ctx, http.MethodPut, object.path, object.body); err != nil {
+			t.Fatalf("PUT %v: %v", object.path, err)
+		}
+	}
+
+	fileName := filepath.Join(t.TempDir(), "test.tf")
+	username, empty := "admin", ""
+	cliParams := CliParams{Username: &username, Bearer_token: &empty, Client_certificate: &empty, Oauth_token_url: &empty}
+	GenerateAll(cliParams, ctx, client, "msg_vpn", "test_vpn", "test", fileName)
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := string(content)
+	for _, want := range []string{
+		`resource "solacebroker_msg_vpn" "test_vpn" {`,
+		`resource "solacebroker_msg_vpn_queue" "test_vpn_orders" {`,
+		`max_msg_size\s+= 1000\n`,
+		`resource "solacebroker_msg_vpn_client_username" "test_vpn_app" {`,
+		`msg_vpn_name\s+= solacebroker_msg_vpn.test_vpn.msg_vpn_name\n`,
+	} {
+		if !regexp.MustCompile(want).MatchString(config) {
+			t.Errorf("expected %q in the generated configuration:\n%v", want, config)
+		}
+	}
+	if strings.Contains(config, "app-secret") {
+		t.Errorf("the generated configuration reveals the write-only password:\n%v", config)
+	}
+}
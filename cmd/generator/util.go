@@ -17,6 +17,7 @@ package generator
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -31,17 +32,38 @@ import (
 )
 
 type CliParams struct {
-	Url                      *string
-	Username                 *string
-	Password                 *string
-	Bearer_token             *string
-	Retries                  *int64
-	Retry_min_interval       *time.Duration
-	Retry_max_interval       *time.Duration
-	Request_timeout_duration *time.Duration
-	Request_min_interval     *time.Duration
-	Insecure_skip_verify     *bool
-	Skip_api_check           *bool
+	Url                         *string
+	Username                    *string
+	Password                    *string
+	Password_file               *string
+	Bearer_token                *string
+	Bearer_token_file           *string
+	Credential_process          *string
+	Client_certificate          *string
+	Client_private_key          *string
+	Client_private_key_password *string
+	Oauth_token_url             *string
+	Oauth_client_id             *string
+	Oauth_client_secret         *string
+	Oauth_scopes                *string
+	Session_login               *bool
+	Retries                     *int64
+	Retry_min_interval          *time.Duration
+	Retry_max_interval          *time.Duration
+	Request_timeout_duration    *time.Duration
+	Request_min_interval        *time.Duration
+	Request_burst_size          *int64
+	Max_concurrent_requests     *int64
+	Insecure_skip_verify        *bool
+	Ca_certificate              *string
+	Tls_server_name             *string
+	Tls_min_version             *string
+	Proxy_url                   *string
+	No_proxy                    *string
+	Request_headers             *string
+	Host_header                 *string
+	Redact_patterns             *string
+	Skip_api_check              *bool
 }
 
 type Color string
@@ -86,26 +108,130 @@ func UpdateCliParamsWithEnv(cliParams CliParams) CliParams {
 	cliParams.Url = StringParamWithEnv("url", cliParams.Url, true, "")
 	cliParams.Username = StringParamWithEnv("username", cliParams.Username, false, "")
 	cliParams.Password = StringParamWithEnv("password", cliParams.Password, false, "")
+	cliParams.Password_file = StringParamWithEnv("password_file", cliParams.Password_file, false, "")
 	cliParams.Bearer_token = StringParamWithEnv("bearer_token", cliParams.Bearer_token, false, "")
+	cliParams.Bearer_token_file = StringParamWithEnv("bearer_token_file", cliParams.Bearer_token_file, false, "")
+	cliParams.Credential_process = StringParamWithEnv("credential_process", cliParams.Credential_process, false, "")
+	resolveCredentialSources(&cliParams)
+	cliParams.Client_certificate = StringParamWithEnv("client_certificate", cliParams.Client_certificate, false, "")
+	cliParams.Client_private_key = StringParamWithEnv("client_private_key", cliParams.Client_private_key, false, "")
+	cliParams.Client_private_key_password = StringParamWithEnv("client_private_key_password", cliParams.Client_private_key_password, false, "")
+	cliParams.Oauth_token_url = StringParamWithEnv("oauth_token_url", cliParams.Oauth_token_url, false, "")
+	cliParams.Oauth_client_id = StringParamWithEnv("oauth_client_id", cliParams.Oauth_client_id, false, "")
+	cliParams.Oauth_client_secret = StringParamWithEnv("oauth_client_secret", cliParams.Oauth_client_secret, false, "")
+	cliParams.Oauth_scopes = StringParamWithEnv("oauth_scopes", cliParams.Oauth_scopes, false, "")
+	cliParams.Session_login = BooleanParamWithEnv("session_login", cliParams.Session_login, false, false)
 	if *cliParams.Bearer_token != "" && (*cliParams.Username != "" || *cliParams.Password != "") {
 		ExitWithError("Cannot provide both bearer_token and basic authentication username/password")
 	}
-	if *cliParams.Bearer_token == "" && *cliParams.Username == "" {
-		ExitWithError("Either bearer_token or basic authentication username/password must be provided")
+	if *cliParams.Client_certificate != "" && (*cliParams.Bearer_token != "" || *cliParams.Username != "" || *cliParams.Password != "") {
+		ExitWithError("Cannot provide client_certificate together with bearer_token or basic authentication username/password")
+	}
+	if *cliParams.Oauth_token_url != "" && (*cliParams.Bearer_token != "" || *cliParams.Username != "" || *cliParams.Password != "" || *cliParams.Client_certificate != "") {
+		ExitWithError("Cannot provide oauth_token_url together with bearer_token, basic authentication username/password or client_certificate")
+	}
+	if *cliParams.Bearer_token == "" && *cliParams.Username == "" && *cliParams.Client_certificate == "" && *cliParams.Oauth_token_url == "" {
+		ExitWithError("Either bearer_token, basic authentication username/password, client_certificate/client_private_key or oauth_token_url/oauth_client_id/oauth_client_secret must be provided")
 	}
 	if *cliParams.Username != "" && *cliParams.Password == "" {
 		ExitWithError("Password must be provided when username is provided")
 	}
+	if *cliParams.Session_login && *cliParams.Username == "" {
+		ExitWithError("Session_login requires basic authentication username/password")
+	}
+	if *cliParams.Client_certificate != "" && *cliParams.Client_private_key == "" {
+		ExitWithError("Client_private_key must be provided when client_certificate is provided")
+	}
+	if *cliParams.Oauth_token_url != "" && (*cliParams.Oauth_client_id == "" || *cliParams.Oauth_client_secret == "") {
+		ExitWithError("Oauth_client_id and oauth_client_secret must be provided when oauth_token_url is provided")
+	}
 	cliParams.Retries = Int64ParamWithEnv("retries", cliParams.Retries, false, semp.DefaultRetries)
 	cliParams.Retry_min_interval = DurationParamWithEnv("retry_min_interval", cliParams.Retry_min_interval, false, semp.DefaultRetryMinInterval)
 	cliParams.Retry_max_interval = DurationParamWithEnv("retry_max_interval", cliParams.Retry_max_interval, false, semp.DefaultRetryMaxInterval)
 	cliParams.Request_timeout_duration = DurationParamWithEnv("request_timeout_duration", cliParams.Request_timeout_duration, false, semp.DefaultRequestTimeout)
 	cliParams.Request_min_interval = DurationParamWithEnv("request_min_interval", cliParams.Request_min_interval, false, semp.DefaultRequestInterval)
+	cliParams.Request_burst_size = Int64ParamWithEnv("request_burst_size", cliParams.Request_burst_size, false, semp.DefaultRequestBurst)
+	if *cliParams.Request_burst_size < 1 {
+		ExitWithError("Request_burst_size must be at least 1")
+	}
+	cliParams.Max_concurrent_requests = Int64ParamWithEnv("max_concurrent_requests", cliParams.Max_concurrent_requests, false, semp.DefaultMaxConcurrent)
+	if *cliParams.Max_concurrent_requests < 1 {
+		ExitWithError("Max_concurrent_requests must be at least 1")
+	}
 	cliParams.Insecure_skip_verify = BooleanParamWithEnv("insecure_skip_verify", cliParams.Insecure_skip_verify, false, false)
+	cliParams.Ca_certificate = StringParamWithEnv("ca_certificate", cliParams.Ca_certificate, false, "")
+	cliParams.Tls_server_name = StringParamWithEnv("tls_server_name", cliParams.Tls_server_name, false, "")
+	cliParams.Tls_min_version = StringParamWithEnv("tls_min_version", cliParams.Tls_min_version, false, "1.2")
+	if _, err := semp.ParseTLSVersion(*cliParams.Tls_min_version); err != nil {
+		ExitWithError(fmt.Sprintf("Invalid value for tls_min_version: %s", *cliParams.Tls_min_version))
+	}
+	cliParams.Proxy_url = StringParamWithEnv("proxy_url", cliParams.Proxy_url, false, "")
+	if *cliParams.Proxy_url != "" {
+		if err := semp.ParseProxyURL(*cliParams.Proxy_url); err != nil {
+			ExitWithError(err.Error())
+		}
+	}
+	cliParams.No_proxy = StringParamWithEnv("no_proxy", cliParams.No_proxy, false, "")
+	cliParams.Request_headers = StringParamWithEnv("request_headers", cliParams.Request_headers, false, "")
+	if _, err := semp.ParseRequestHeaders(*cliParams.Request_headers); err != nil {
+		ExitWithError(err.Error())
+	}
+	cliParams.Host_header = StringParamWithEnv("host_header", cliParams.Host_header, false, "")
+	cliParams.Redact_patterns = StringParamWithEnv("redact_patterns", cliParams.Redact_patterns, false, "")
+	if _, err := semp.ParseRedactPatterns(RedactPatterns(*cliParams.Redact_patterns)); err != nil {
+		ExitWithError(err.Error())
+	}
 	cliParams.Skip_api_check = BooleanParamWithEnv("skip_api_check", cliParams.Skip_api_check, false, false)
 	return cliParams
 }
 
+// RedactPatterns splits the newline-separated redact_patterns parameter
+func RedactPatterns(s string) []string {
+	var patterns []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
+}
+
+// resolveCredentialSources sets the credentials provided by password_file, bearer_token_file and credential_process
+func resolveCredentialSources(cliParams *CliParams) {
+	if *cliParams.Password_file != "" {
+		if *cliParams.Password != "" {
+			ExitWithError("Cannot provide both password and password_file")
+		}
+		password, err := semp.ReadSecretFile(*cliParams.Password_file)
+		if err != nil {
+			ExitWithError("Unable to read password_file, " + err.Error())
+		}
+		cliParams.Password = &password
+	}
+	if *cliParams.Bearer_token_file != "" {
+		if *cliParams.Bearer_token != "" {
+			ExitWithError("Cannot provide both bearer_token and bearer_token_file")
+		}
+		bearerToken, err := semp.ReadSecretFile(*cliParams.Bearer_token_file)
+		if err != nil {
+			ExitWithError("Unable to read bearer_token_file, " + err.Error())
+		}
+		cliParams.Bearer_token = &bearerToken
+	}
+	if *cliParams.Credential_process != "" {
+		if *cliParams.Username != "" || *cliParams.Password != "" || *cliParams.Bearer_token != "" {
+			ExitWithError("Cannot provide credential_process together with bearer_token or basic authentication username/password")
+		}
+		credentials, err := semp.RunCredentialProcess(context.Background(), *cliParams.Credential_process)
+		if err != nil {
+			ExitWithError("Unable to get credentials from credential_process, " + err.Error())
+		}
+		cliParams.Username = &credentials.Username
+		cliParams.Password = &credentials.Password
+		cliParams.Bearer_token = &credentials.BearerToken
+	}
+}
+
 func StringParamWithEnv(name string, value *string, isMandatory bool, fallback string) *string {
 	if value != nil {
 		return value
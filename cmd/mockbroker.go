@@ -0,0 +1,66 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"terraform-provider-solacebroker/internal/semp/semptest"
+
+	"github.com/spf13/cobra"
+)
+
+// mockBrokerCmd represents the mock-broker command
+var mockBrokerCmd = &cobra.Command{
+	Use:   "mock-broker",
+	Short: "Serves an in-memory SEMP v2 config API for testing",
+	Long: `Serves an in-memory SEMP v2 config API for the broker objects known to this provider, for testing the provider and the generator without a broker.
+Configuration is kept in memory only and is lost when the command ends.
+
+Example:
+  terraform-provider-solacebroker mock-broker --port=8080 --username=admin --password=admin
+
+The provider and generator can then be used with url http://localhost:8080.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		port, _ := flags.GetInt("port")
+		username, _ := flags.GetString("username")
+		password, _ := flags.GetString("password")
+		pageSize, _ := flags.GetInt("page_size")
+		options := []semptest.Option{semptest.PageSize(pageSize)}
+		if username != "" {
+			options = append(options, semptest.BasicAuth(username, password))
+		}
+		address := net.JoinHostPort("localhost", strconv.Itoa(port))
+		fmt.Printf("Serving mock broker at http://%s\n", address)
+		if err := http.ListenAndServe(address, semptest.New(options...)); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockBrokerCmd)
+	mockBrokerCmd.Flags().Int("port", 8080, "Port to serve the SEMP API on")
+	mockBrokerCmd.Flags().String("username", "admin", "Username required for basic authentication, no authentication if empty")
+	mockBrokerCmd.Flags().String("password", "admin", "Password required for basic authentication")
+	mockBrokerCmd.Flags().Int("page_size", semptest.DefaultPageSize, "Number of objects returned per page of a collection")
+}
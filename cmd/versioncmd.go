@@ -18,7 +18,7 @@ package cmd
 import (
 	"fmt"
 	"terraform-provider-solacebroker/internal/broker"
-	"terraform-provider-solacebroker/internal/broker/generated"
+	_ "terraform-provider-solacebroker/internal/broker/generated"
 
 	"github.com/spf13/cobra"
 )
@@ -36,7 +36,9 @@ var versionCmd = &cobra.Command{
 	Short: "Provides version information about the current binary",
 	Long:  "",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Terraform Provider for Solace %s platform, version: %s, based on Semp version %s\n", BrokerPlatformName[generated.Platform], broker.ProviderVersion, generated.SempVersion)
+		for _, platform := range broker.SupportedPlatforms() {
+			fmt.Printf("Terraform Provider for Solace %s platform, version: %s, based on Semp version %s\n", BrokerPlatformName[platform], broker.ProviderVersion, broker.SempDetails[platform].SempVersion)
+		}
 	},
 }
 
@@ -62,4 +62,10 @@ type AttributeInfo struct {
 	Int64Validators     []validator.Int64
 	BoolValidators      []validator.Bool
 	Default             any
+	// MinSempVersion is the SEMP API version the attribute is available since, empty if it is not known
+	MinSempVersion string
+	// WriteOnly attributes are sent to the broker but not stored in the state, see withWriteOnlyArguments
+	WriteOnly bool
+	// WriteOnlyVersion attributes are only stored in the state, changing them sends their write-only attribute again
+	WriteOnlyVersion bool
 }
@@ -112,7 +112,7 @@ func (c *ObjectConverter) ToTerraform(v any) (tftypes.Value, error) {
 	tfData := map[string]tftypes.Value{}
 	for _, sempAttribute := range c.attributes {
 		v, ok := m[sempAttribute.SempName]
-		if ok {
+		if ok && !sempAttribute.WriteOnly {
 			tfv, err := sempAttribute.Converter.ToTerraform(v)
 			if err != nil {
 				return tftypes.Value{}, fmt.Errorf("error converting broker SEMP response for attribute %v: %s", sempAttribute.TerraformName, err)
@@ -137,6 +137,10 @@ func (c *ObjectConverter) FromTerraform(v tftypes.Value) (any, error) {
 		return nil, err
 	}
 	for _, attr := range c.attributes {
+		if attr.WriteOnlyVersion {
+			// not a broker attribute
+			continue
+		}
 		v, ok := tfAttributes[attr.TerraformName]
 		if ok && v.IsKnown() && !v.IsNull() {
 			v, err := attr.Converter.FromTerraform(v)
@@ -0,0 +1,149 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"terraform-provider-solacebroker/internal/broker"
+	_ "terraform-provider-solacebroker/internal/broker/generated"
+	"terraform-provider-solacebroker/internal/semp/semptest"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// dynamicValue returns an object of the given type with the given attribute values, the other attributes are null
+func dynamicValue(t *testing.T, objectType tftypes.Type, values map[string]tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+	object := tftypes.NewValue(objectType, nil)
+	if values != nil {
+		attributes := map[string]tftypes.Value{}
+		for name, attributeType := range objectType.(tftypes.Object).AttributeTypes {
+			attributes[name] = tftypes.NewValue(attributeType, nil)
+			if value, ok := values[name]; ok {
+				attributes[name] = value
+			}
+		}
+		object = tftypes.NewValue(objectType, attributes)
+	}
+	value, err := tfprotov6.NewDynamicValue(objectType, object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &value
+}
+
+func checkDiagnostics(t *testing.T, operation string, diagnostics []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, d := range diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%v failed: %v: %v", operation, d.Summary, d.Detail)
+		}
+	}
+}
+
+func TestResourceLifecycle(t *testing.T) {
+	b := semptest.New(semptest.BasicAuth("admin", "admin"))
+	server := httptest.NewServer(b)
+	defer server.Close()
+	ctx := context.Background()
+	provider, err := providerserver.NewProtocol6WithError(broker.New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := provider.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configureResponse, err := provider.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		Config: dynamicValue(t, schemas.Provider.ValueType(), map[string]tftypes.Value{
+			"url":                  tftypes.NewValue(tftypes.String, server.URL),
+			"username":             tftypes.NewValue(tftypes.String, "admin"),
+			"password":             tftypes.NewValue(tftypes.String, "admin"),
+			"retries":              tftypes.NewValue(tftypes.Number, 0),
+			"request_min_interval": tftypes.NewValue(tftypes.String, "0s"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, "configure", configureResponse.Diagnostics)
+
+	const typeName = "solacebroker_msg_vpn"
+	objectType := schemas.ResourceSchemas[typeName].ValueType()
+	null := dynamicValue(t, objectType, nil)
+	// apply plans and applies a configuration, it returns the new state and private state
+	apply := func(operation string, config *tfprotov6.DynamicValue, state *tfprotov6.DynamicValue, private []byte) (*tfprotov6.DynamicValue, []byte) {
+		t.Helper()
+		planResponse, err := provider.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+			TypeName: typeName, PriorState: state, ProposedNewState: config, Config: config, PriorPrivate: private})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkDiagnostics(t, operation+" plan", planResponse.Diagnostics)
+		applyResponse, err := provider.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+			TypeName: typeName, PriorState: state, PlannedState: planResponse.PlannedState, Config: config,
+			PlannedPrivate: planResponse.PlannedPrivate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkDiagnostics(t, operation, applyResponse.Diagnostics)
+		return applyResponse.NewState, applyResponse.Private
+	}
+	vpnConfig := func(maxMsgSpoolUsage int) *tfprotov6.DynamicValue {
+		return dynamicValue(t, objectType, map[string]tftypes.Value{
+			"msg_vpn_name":        tftypes.NewValue(tftypes.String, "test"),
+			"enabled":             tftypes.NewValue(tftypes.Bool, true),
+			"max_msg_spool_usage": tftypes.NewValue(tftypes.Number, maxMsgSpoolUsage),
+		})
+	}
+	checkObject := func(want int) {
+		t.Helper()
+		vpn, ok := b.Object("/msgVpns/test")
+		if !ok {
+			t.Fatal("expected the broker to have the message VPN")
+		}
+		if vpn["enabled"] != true || fmt.Sprint(vpn["maxMsgSpoolUsage"]) != fmt.Sprint(want) {
+			t.Errorf("got message VPN %v, want it enabled with a maximum spool usage of %v", vpn, want)
+		}
+	}
+
+	state, private := apply("create", vpnConfig(10), null, nil)
+	checkObject(10)
+
+	readResponse, err := provider.ReadResource(ctx, &tfprotov6.ReadResourceRequest{TypeName: typeName, CurrentState: state, Private: private})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, "read", readResponse.Diagnostics)
+	if readResponse.NewState == nil {
+		t.Fatal("expected the message VPN to be read")
+	}
+
+	state, private = apply("update", vpnConfig(20), readResponse.NewState, readResponse.Private)
+	checkObject(20)
+
+	apply("delete", null, state, private)
+	if _, ok := b.Object("/msgVpns/test"); ok {
+		t.Error("expected the message VPN to be deleted")
+	}
+}
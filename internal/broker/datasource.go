@@ -62,7 +62,7 @@ func (ds *brokerDataSource) Configure(_ context.Context, request datasource.Conf
 	if request.ProviderData == nil {
 		return
 	}
-	client, ok := request.ProviderData.(*semp.Client)
+	client, ok := request.ProviderData.(*brokerClient)
 	if !ok {
 		response.Diagnostics.AddError(
 			"Unexpected datasource configuration",
@@ -74,12 +74,28 @@ func (ds *brokerDataSource) Configure(_ context.Context, request datasource.Conf
 }
 
 func (ds *brokerDataSource) Read(ctx context.Context, request datasource.ReadRequest, response *datasource.ReadResponse) {
+	ctx, finish := ds.startOperation(ctx, "ReadDataSource", request.Config.Raw, &response.Diagnostics)
+	defer finish()
 	client := ds.client
 	if err := checkBrokerRequirements(ctx, client); err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
 		return
 	}
-	sempPath, err := resolveSempPath(ds.pathTemplate, ds.identifyingAttributes, request.Config.Raw)
+	ds, err := ds.onBrokerPlatform()
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
+	if err := ds.checkSempVersion(); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Unsupported data source", err)
+		return
+	}
+	config, err := ds.withDefaultMsgVpnName(request.Config.Raw)
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Error generating SEMP path", err)
+		return
+	}
+	sempPath, err := resolveSempPath(ds.pathTemplate, ds.identifyingAttributes, config)
 	if err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Error generating SEMP path", err)
 		return
@@ -87,7 +103,7 @@ func (ds *brokerDataSource) Read(ctx context.Context, request datasource.ReadReq
 	sempData, err := client.RequestWithoutBody(ctx, http.MethodGet, sempPath)
 	if err != nil {
 		if errors.Is(err, semp.ErrResourceNotFound) {
-			addErrorToDiagnostics(&response.Diagnostics, fmt.Sprintf("Detected missing data source %v", sempPath), errors.Unwrap(err))
+			addErrorToDiagnostics(&response.Diagnostics, fmt.Sprintf("Detected missing data source %v", sempPath), err)
 		} else {
 			addErrorToDiagnostics(&response.Diagnostics, "SEMP call failed", err)
 		}
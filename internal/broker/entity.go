@@ -19,7 +19,6 @@ package broker
 import (
 	"fmt"
 	"reflect"
-	"terraform-provider-solacebroker/internal/semp"
 
 	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -41,11 +40,20 @@ type brokerEntityBase struct {
 	pathTemplate          string
 	postPathTemplate      string
 	terraformName         string
+	platform              string
+	minSempVersion        string
 	objectType            objectType
 	identifyingAttributes []*AttributeInfo
 	attributes            []*AttributeInfo
 	converter             *ObjectConverter
-	client                *semp.Client
+	client                *brokerClient
+	// platformVariants holds the variants of an object type registered for several broker platforms, see forPlatform
+	platformVariants []*brokerEntityBase
+	// unsupportedAttributes are the attributes of the other variants that a platform variant does not have
+	unsupportedAttributes []string
+	// platformValidatedAttributes are the attributes whose validators are not in the merged schema, including nested
+	// attributes
+	platformValidatedAttributes []platformValidatedAttribute
 }
 
 func copyMatchingFields(prefix string, in reflect.Value, out reflect.Value) {
@@ -0,0 +1,224 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = &importIDFunction{}
+	_ function.Function = &sempPathFunction{}
+	_ function.Function = &topicMatchesFunction{}
+	_ function.Function = &validTopicFunction{}
+)
+
+// functions are the provider-defined functions, they do not depend on the provider configuration
+var functions = []func() function.Function{
+	func() function.Function { return &importIDFunction{} },
+	func() function.Function { return &sempPathFunction{} },
+	func() function.Function { return &topicMatchesFunction{} },
+	func() function.Function { return &validTopicFunction{} },
+}
+
+var (
+	resourceTypeParameter = function.StringParameter{
+		Name:                "resource_type",
+		MarkdownDescription: "The resource type, such as `solacebroker_msg_vpn_queue`. The `solacebroker_` prefix may be left out.",
+	}
+	attributesParameter = function.MapParameter{
+		Name:                "attributes",
+		ElementType:         types.StringType,
+		MarkdownDescription: "The identifying attributes of the object, such as `{ msg_vpn_name = \"default\", queue_name = \"q1\" }`. Other attributes are ignored.",
+	}
+	syntaxDescription = "The topic syntax, `smf` or `mqtt`."
+)
+
+// resourceIdentifiers returns the object type of a resource type and its identifying attributes in the order of the
+// import identifier, with their values. The object type of the default platform is used.
+func resourceIdentifiers(resourceType string, attributes map[string]string) (EntityInputs, []string, *function.FuncError) {
+	variants, ok := resourceVariants[strings.TrimPrefix(resourceType, providerTypeName+"_")]
+	if !ok {
+		return EntityInputs{}, nil, function.NewArgumentFuncError(0, fmt.Sprintf("unknown resource type %v", resourceType))
+	}
+	inputs := variants[0]
+	for _, v := range variants {
+		if platformOf(v.Platform) == SempDetail.Platform {
+			inputs = v
+		}
+	}
+	var values []string
+	for _, attr := range identifyingAttributesOf(inputs) {
+		v, ok := attributes[attr.TerraformName]
+		if !ok {
+			return EntityInputs{}, nil, function.NewArgumentFuncError(1, fmt.Sprintf("%v is required to identify %v", attr.TerraformName, resourceType))
+		}
+		values = append(values, v)
+	}
+	return inputs, values, nil
+}
+
+type importIDFunction struct{}
+
+func (f *importIDFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "import_id"
+}
+
+func (f *importIDFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary:             "Import identifier of a resource",
+		MarkdownDescription: "Returns the import identifier of a resource from its identifying attributes, which are URL-encoded and separated by `/` in the order of the resource documentation. The identifier of singleton objects is the empty string.",
+		Parameters:          []function.Parameter{resourceTypeParameter, attributesParameter},
+		Return:              function.StringReturn{},
+	}
+}
+
+func (f *importIDFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var resourceType string
+	var attributes map[string]string
+	response.Error = request.Arguments.Get(ctx, &resourceType, &attributes)
+	if response.Error != nil {
+		return
+	}
+	_, values, funcErr := resourceIdentifiers(resourceType, attributes)
+	if funcErr != nil {
+		response.Error = funcErr
+		return
+	}
+	for i, v := range values {
+		values[i] = url.PathEscape(v)
+	}
+	response.Error = response.Result.Set(ctx, strings.Join(values, "/"))
+}
+
+type sempPathFunction struct{}
+
+func (f *sempPathFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "semp_path"
+}
+
+func (f *sempPathFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary:             "SEMP path of a broker object",
+		MarkdownDescription: "Returns the path of the object of a resource in the SEMP v2 configuration API, such as `/msgVpns/default/queues/q1`, from its identifying attributes. The path is relative to the SEMP API base path `/SEMP/v2/config`.",
+		Parameters:          []function.Parameter{resourceTypeParameter, attributesParameter},
+		Return:              function.StringReturn{},
+	}
+}
+
+func (f *sempPathFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var resourceType string
+	var attributes map[string]string
+	response.Error = request.Arguments.Get(ctx, &resourceType, &attributes)
+	if response.Error != nil {
+		return
+	}
+	inputs, values, funcErr := resourceIdentifiers(resourceType, attributes)
+	if funcErr != nil {
+		response.Error = funcErr
+		return
+	}
+	identifiers := map[string]string{}
+	for i, attr := range identifyingAttributesOf(inputs) {
+		identifiers[attr.SempName] = values[i]
+	}
+	sempPath, err := resolvePathTemplate(inputs.PathTemplate, identifiers)
+	if err != nil {
+		response.Error = function.NewFuncError(err.Error())
+		return
+	}
+	response.Error = response.Result.Set(ctx, sempPath)
+}
+
+type topicMatchesFunction struct{}
+
+func (f *topicMatchesFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "topic_matches"
+}
+
+func (f *topicMatchesFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary:             "Match a topic against a topic subscription",
+		MarkdownDescription: "Tells if a topic subscription matches a topic. In the SMF syntax, `*` at the end of a level matches the rest of the level and a last level of `>` matches one or more levels, the `#share/<group>/` and `#noexport/` prefixes are ignored. In the MQTT syntax, a `+` level matches one level and a last level of `#` matches the parent level and any number of levels.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "subscription", MarkdownDescription: "The topic subscription, which may contain wildcards."},
+			function.StringParameter{Name: "topic", MarkdownDescription: "The topic."},
+		},
+		VariadicParameter: function.StringParameter{Name: "syntax", MarkdownDescription: syntaxDescription + " The default is `smf`."},
+		Return:            function.BoolReturn{},
+	}
+}
+
+func (f *topicMatchesFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var subscription, topic string
+	var syntax []string
+	response.Error = request.Arguments.Get(ctx, &subscription, &topic, &syntax)
+	if response.Error != nil {
+		return
+	}
+	if len(syntax) > 1 {
+		response.Error = function.NewArgumentFuncError(2, "only one topic syntax may be given")
+		return
+	}
+	s := smfSyntax
+	if len(syntax) == 1 {
+		s = syntax[0]
+	}
+	matches, err := topicMatches(subscription, topic, s)
+	if err != nil {
+		response.Error = function.NewArgumentFuncError(2, err.Error())
+		return
+	}
+	response.Error = response.Result.Set(ctx, matches)
+}
+
+type validTopicFunction struct{}
+
+func (f *validTopicFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "valid_topic"
+}
+
+func (f *validTopicFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary:             "Validate a topic or topic subscription",
+		MarkdownDescription: fmt.Sprintf("Tells if a topic or topic subscription is valid in a topic syntax. Topics may not be empty, be longer than %d bytes or have more than %d levels. SMF topics may not have empty levels. In MQTT topics, the `+` and `#` wildcards must be whole levels and `#` must be the last level.", maxTopicLength, maxTopicLevels),
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "topic", MarkdownDescription: "The topic or topic subscription."},
+			function.StringParameter{Name: "syntax", MarkdownDescription: syntaxDescription},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *validTopicFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var topic, syntax string
+	response.Error = request.Arguments.Get(ctx, &topic, &syntax)
+	if response.Error != nil {
+		return
+	}
+	if err := checkTopicSyntax(syntax); err != nil {
+		response.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+	response.Error = response.Result.Set(ctx, validateTopic(topic, syntax) == nil)
+}
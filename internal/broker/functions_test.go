@@ -0,0 +1,157 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func runFunction(t *testing.T, f function.Function, arguments ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+	ctx := context.Background()
+	definition := &function.DefinitionResponse{}
+	f.Definition(ctx, function.DefinitionRequest{}, definition)
+	response := &function.RunResponse{Result: function.NewResultData(definition.Definition.Return.GetType().ValueType(ctx))}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(arguments)}, response)
+	return response.Result.Value(), response.Error
+}
+
+func TestIdentifierFunctions(t *testing.T) {
+	resourceVariants["msg_vpn_queue_test"] = []EntityInputs{msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")}
+	t.Cleanup(func() { delete(resourceVariants, "msg_vpn_queue_test") })
+	attributes := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"queue_name":   types.StringValue("a/b"),
+		"msg_vpn_name": types.StringValue("default"),
+		"other":        types.StringValue("ignored"),
+	})
+	tests := []struct {
+		name         string
+		function     function.Function
+		resourceType string
+		want         string
+	}{
+		{name: "import id", function: &importIDFunction{}, resourceType: "solacebroker_msg_vpn_queue_test", want: "default/a%2Fb"},
+		{name: "import id without prefix", function: &importIDFunction{}, resourceType: "msg_vpn_queue_test", want: "default/a%2Fb"},
+		{name: "semp path", function: &sempPathFunction{}, resourceType: "solacebroker_msg_vpn_queue_test", want: "/msgVpns/default/queues/a%2Fb"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, funcErr := runFunction(t, test.function, types.StringValue(test.resourceType), attributes)
+			if funcErr != nil {
+				t.Fatal(funcErr)
+			}
+			if result.(types.String).ValueString() != test.want {
+				t.Errorf("got %v, want %v", result, test.want)
+			}
+		})
+	}
+	if _, funcErr := runFunction(t, &importIDFunction{}, types.StringValue("solacebroker_unknown"), attributes); funcErr == nil {
+		t.Error("expected an error for an unknown resource type")
+	}
+	missing := types.MapValueMust(types.StringType, map[string]attr.Value{"queue_name": types.StringValue("q1")})
+	if _, funcErr := runFunction(t, &sempPathFunction{}, types.StringValue("msg_vpn_queue_test"), missing); funcErr == nil {
+		t.Error("expected an error for a missing identifying attribute")
+	}
+}
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		subscription string
+		topic        string
+		syntax       string
+		want         bool
+	}{
+		{subscription: "a/b/c", topic: "a/b/c", syntax: smfSyntax, want: true},
+		{subscription: "a/*/c", topic: "a/b/c", syntax: smfSyntax, want: true},
+		{subscription: "a/b*/c", topic: "a/bcd/c", syntax: smfSyntax, want: true},
+		{subscription: "a/b*/c", topic: "a/cd/c", syntax: smfSyntax},
+		{subscription: "a/b*c", topic: "a/bxc", syntax: smfSyntax},
+		{subscription: "a/>", topic: "a/b/c", syntax: smfSyntax, want: true},
+		{subscription: "a/>", topic: "a", syntax: smfSyntax},
+		{subscription: "a/>/c", topic: "a/b/c", syntax: smfSyntax},
+		{subscription: "a/*", topic: "a/b/c", syntax: smfSyntax},
+		{subscription: "#share/group/a/*", topic: "a/b", syntax: smfSyntax, want: true},
+		{subscription: "#noexport/a/b", topic: "a/b", syntax: smfSyntax, want: true},
+		{subscription: "a/+/c", topic: "a/b/c", syntax: mqttSyntax, want: true},
+		{subscription: "a/#", topic: "a", syntax: mqttSyntax, want: true},
+		{subscription: "a/#", topic: "a/b/c", syntax: mqttSyntax, want: true},
+		{subscription: "a/+", topic: "a/b/c", syntax: mqttSyntax},
+		{subscription: "#", topic: "$SYS/a", syntax: mqttSyntax},
+		{subscription: "a/*", topic: "a/b", syntax: mqttSyntax},
+	}
+	for _, test := range tests {
+		t.Run(test.syntax+" "+test.subscription+" "+test.topic, func(t *testing.T) {
+			got, err := topicMatches(test.subscription, test.topic, test.syntax)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+	result, funcErr := runFunction(t, &topicMatchesFunction{}, types.StringValue("a/>"), types.StringValue("a/b"),
+		types.TupleValueMust([]attr.Type{}, []attr.Value{}))
+	if funcErr != nil || !result.(types.Bool).ValueBool() {
+		t.Errorf("expected the SMF syntax by default, got %v %v", result, funcErr)
+	}
+	_, funcErr = runFunction(t, &topicMatchesFunction{}, types.StringValue("a/>"), types.StringValue("a/b"),
+		types.TupleValueMust([]attr.Type{types.StringType}, []attr.Value{types.StringValue("amqp")}))
+	if funcErr == nil {
+		t.Error("expected an error for an unknown topic syntax")
+	}
+}
+
+func TestValidTopic(t *testing.T) {
+	long := ""
+	for len(long) <= maxTopicLength {
+		long += "a/"
+	}
+	tests := []struct {
+		topic  string
+		syntax string
+		want   bool
+	}{
+		{topic: "a/b*/>", syntax: smfSyntax, want: true},
+		{topic: "#share/group/a/b", syntax: smfSyntax, want: true},
+		{topic: "a//b", syntax: smfSyntax},
+		{topic: "", syntax: smfSyntax},
+		{topic: long, syntax: smfSyntax},
+		{topic: "a//b/+/#", syntax: mqttSyntax, want: true},
+		{topic: "a/b+", syntax: mqttSyntax},
+		{topic: "a/#/b", syntax: mqttSyntax},
+	}
+	for _, test := range tests {
+		t.Run(test.syntax+" "+test.topic, func(t *testing.T) {
+			result, funcErr := runFunction(t, &validTopicFunction{}, types.StringValue(test.topic), types.StringValue(test.syntax))
+			if funcErr != nil {
+				t.Fatal(funcErr)
+			}
+			if result.(types.Bool).ValueBool() != test.want {
+				t.Errorf("got %v, want %v", result, test.want)
+			}
+		})
+	}
+	if _, funcErr := runFunction(t, &validTopicFunction{}, types.StringValue("a"), types.StringValue("amqp")); funcErr == nil {
+		t.Error("expected an error for an unknown topic syntax")
+	}
+}
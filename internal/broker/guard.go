@@ -0,0 +1,95 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var ErrMsgVpnNotAllowed = errors.New("changes to this object are not allowed by the provider configuration")
+
+// msgVpnGuard restricts the Message VPNs whose objects resources may change. Names may contain shell-style wildcards,
+// which are not valid in Message VPN names.
+type msgVpnGuard struct {
+	allowed []string
+	denied  []string
+}
+
+func newMsgVpnGuard(allowed, denied []string) (msgVpnGuard, error) {
+	for _, pattern := range append(append([]string{}, allowed...), denied...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return msgVpnGuard{}, fmt.Errorf("invalid Message VPN pattern %v: %w", pattern, err)
+		}
+	}
+	return msgVpnGuard{allowed: allowed, denied: denied}, nil
+}
+
+// check returns an error if the object at a resolved SEMP path may not be changed. Objects that are not within a
+// Message VPN may only be changed if allowed_msg_vpns is not set.
+func (g msgVpnGuard) check(sempPath string) error {
+	if len(g.allowed) == 0 && len(g.denied) == 0 {
+		return nil
+	}
+	msgVpnName, ok := msgVpnOfPath(sempPath)
+	switch {
+	case !ok && len(g.allowed) != 0:
+		return fmt.Errorf("%w: %v is not within one of allowed_msg_vpns", ErrMsgVpnNotAllowed, sempPath)
+	case !ok:
+		return nil
+	case matchesAny(g.denied, msgVpnName):
+		return fmt.Errorf("%w: Message VPN %v is in denied_msg_vpns", ErrMsgVpnNotAllowed, msgVpnName)
+	case len(g.allowed) != 0 && !matchesAny(g.allowed, msgVpnName):
+		return fmt.Errorf("%w: Message VPN %v is not in allowed_msg_vpns", ErrMsgVpnNotAllowed, msgVpnName)
+	}
+	return nil
+}
+
+// msgVpnOfPath returns the name of the Message VPN of the object at a resolved SEMP path
+func msgVpnOfPath(sempPath string) (string, bool) {
+	rest, ok := strings.CutPrefix(sempPath, "/msgVpns/")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	name, err := url.PathUnescape(name)
+	return name, err == nil && name != ""
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMsgVpnGuard returns an error if the provider configuration does not allow changes to the object, the object
+// path is checked rather than the collection path objects may be created with
+func (r *brokerResource) checkMsgVpnGuard(data tftypes.Value) error {
+	sempPath, err := resolveSempPath(r.pathTemplate, r.identifyingAttributes, data)
+	if err != nil {
+		return err
+	}
+	return r.client.msgVpnGuard.check(sempPath)
+}
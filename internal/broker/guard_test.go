@@ -0,0 +1,121 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestMsgVpnGuard(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		denied   []string
+		sempPath string
+		wantErr  bool
+	}{
+		{name: "no guard", sempPath: "/msgVpns/prod/queues/q1"},
+		{name: "allowed", allowed: []string{"team-a-*"}, sempPath: "/msgVpns/team-a-dev/queues/q1"},
+		{name: "allowed Message VPN", allowed: []string{"team-a-*"}, sempPath: "/msgVpns/team-a-dev"},
+		{name: "not allowed", allowed: []string{"team-a-*"}, sempPath: "/msgVpns/team-b/queues/q1", wantErr: true},
+		{name: "escaped name", allowed: []string{"team a"}, sempPath: "/msgVpns/team%20a/queues/q1"},
+		{name: "outside Message VPN", allowed: []string{"team-a-*"}, sempPath: "/dmrClusters/cluster1", wantErr: true},
+		{name: "denied", allowed: []string{"team-a-*"}, denied: []string{"team-a-prod"}, sempPath: "/msgVpns/team-a-prod/queues/q1", wantErr: true},
+		{name: "not denied", denied: []string{"prod"}, sempPath: "/msgVpns/dev/queues/q1"},
+		{name: "outside Message VPN not denied", denied: []string{"prod"}, sempPath: "/"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			guard, err := newMsgVpnGuard(test.allowed, test.denied)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = guard.check(test.sempPath)
+			if (err != nil) != test.wantErr {
+				t.Errorf("check(%v) error = %v, wantErr %v", test.sempPath, err, test.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrMsgVpnNotAllowed) {
+				t.Errorf("expected %v, got %v", ErrMsgVpnNotAllowed, err)
+			}
+		})
+	}
+	if _, err := newMsgVpnGuard([]string{"team-["}, nil); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
+
+func TestGuardedProviderRefusesChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+	}{
+		{name: "read only", config: map[string]tftypes.Value{"read_only": tftypes.NewValue(tftypes.Bool, true)}},
+		{name: "allowed Message VPNs", config: map[string]tftypes.Value{"allowed_msg_vpns": tftypes.NewValue(
+			tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "team-a")})}},
+		{name: "denied Message VPNs", config: map[string]tftypes.Value{"denied_msg_vpns": tftypes.NewValue(
+			tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "default")})}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var changes atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				changes.Add(1)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"data":{},"meta":{"responseCode":200}}`))
+			}))
+			t.Cleanup(server.Close)
+			config := fakeBrokerProviderConfig(server.URL, true)
+			for name, value := range test.config {
+				config[name] = value
+			}
+			r := brokerResource(newBrokerResource(msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")))
+			r.client = configureTestProvider(t, config)
+			ctx := context.Background()
+			data := r.msgVpnTestValue(ctx, tftypes.NewValue(tftypes.String, "default"))
+
+			createResponse := &resource.CreateResponse{}
+			r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Schema: r.schema, Raw: data}}, createResponse)
+			updateResponse := &resource.UpdateResponse{}
+			r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Schema: r.schema, Raw: data}}, updateResponse)
+			deleteResponse := &resource.DeleteResponse{}
+			r.Delete(ctx, resource.DeleteRequest{State: tfsdk.State{Schema: r.schema, Raw: data}}, deleteResponse)
+
+			for _, diags := range []diag.Diagnostics{createResponse.Diagnostics, updateResponse.Diagnostics, deleteResponse.Diagnostics} {
+				if diags.ErrorsCount() == 0 {
+					t.Error("expected the change to be refused")
+				}
+			}
+			if detail := createResponse.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, "not allowed") {
+				t.Errorf("unexpected diagnostic %v", detail)
+			}
+			if n := changes.Load(); n != 0 {
+				t.Errorf("expected no requests to reach the broker, got %d", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const msgVpnNameAttribute = "msg_vpn_name"
+
+var ErrMissingMsgVpnName = errors.New("msg_vpn_name must be set if the provider has no default_msg_vpn_name")
+
+// inMsgVpn tells if the objects of a path template are contained within a Message VPN
+func inMsgVpn(pathTemplate string) bool {
+	return strings.HasPrefix(pathTemplate, "/msgVpns/{msgVpnName}/")
+}
+
+// optionalMsgVpnName makes the msg_vpn_name attribute of objects within a Message VPN optional, it defaults to the
+// provider default_msg_vpn_name
+func optionalMsgVpnName(attr schema.Attribute) schema.Attribute {
+	s, ok := attr.(schema.StringAttribute)
+	if !ok {
+		return attr
+	}
+	s.Required = false
+	s.Optional = true
+	s.Computed = true
+	s.MarkdownDescription += " If not set, the `default_msg_vpn_name` of the provider is used."
+	if s.PlanModifiers != nil {
+		// keep the Message VPN of existing objects in the plan, ModifyPlan replaces them if the default changes
+		s.PlanModifiers = append([]planmodifier.String{stringplanmodifier.UseStateForUnknown()}, s.PlanModifiers...)
+	}
+	return s
+}
+
+// defaultMsgVpnName returns the provider default_msg_vpn_name, or an empty string if it is not set
+func (b *brokerEntityBase) defaultMsgVpnName() string {
+	if b.client == nil {
+		return ""
+	}
+	return b.client.defaultMsgVpnName
+}
+
+// withDefaultMsgVpnName sets msg_vpn_name of an object within a Message VPN to the provider default_msg_vpn_name if
+// it is not set
+func (b *brokerEntityBase) withDefaultMsgVpnName(v tftypes.Value) (tftypes.Value, error) {
+	if !inMsgVpn(b.pathTemplate) || v.IsNull() || !v.IsKnown() {
+		return v, nil
+	}
+	m, err := copyObjectValues(v)
+	if err != nil {
+		return v, err
+	}
+	if name, ok := m[msgVpnNameAttribute]; !ok || !name.IsNull() {
+		return v, nil
+	}
+	if b.defaultMsgVpnName() == "" {
+		return v, ErrMissingMsgVpnName
+	}
+	m[msgVpnNameAttribute] = tftypes.NewValue(tftypes.String, b.defaultMsgVpnName())
+	return tftypes.NewValue(v.Type(), m), nil
+}
+
+// planDefaultMsgVpnName sets msg_vpn_name of objects within a Message VPN to the provider default_msg_vpn_name if it
+// is not configured. Objects are replaced if they move to another Message VPN because the default changed.
+func (r *brokerResource) planDefaultMsgVpnName(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	if !inMsgVpn(r.pathTemplate) || request.Plan.Raw.IsNull() || r.client == nil {
+		// the provider is not configured yet if its configuration has unknown values
+		return
+	}
+	msgVpnName := path.Root(msgVpnNameAttribute)
+	var configured types.String
+	response.Diagnostics.Append(request.Config.GetAttribute(ctx, msgVpnName, &configured)...)
+	if response.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+	if r.defaultMsgVpnName() == "" {
+		response.Diagnostics.AddAttributeError(msgVpnName, "Missing Message VPN", ErrMissingMsgVpnName.Error())
+		return
+	}
+	response.Diagnostics.Append(response.Plan.SetAttribute(ctx, msgVpnName, r.defaultMsgVpnName())...)
+	if request.State.Raw.IsNull() {
+		return
+	}
+	var current types.String
+	response.Diagnostics.Append(request.State.GetAttribute(ctx, msgVpnName, &current)...)
+	if current.ValueString() != r.defaultMsgVpnName() {
+		response.RequiresReplace = append(response.RequiresReplace, msgVpnName)
+	}
+}
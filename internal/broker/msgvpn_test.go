@@ -0,0 +1,185 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func msgVpnTestInputs(terraformName, pathTemplate string) EntityInputs {
+	attributes := []*AttributeInfo{
+		{BaseType: String, SempName: "msgVpnName", TerraformName: "msg_vpn_name", Identifying: true, Required: true, RequiresReplace: true,
+			Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}},
+	}
+	if inMsgVpn(pathTemplate) {
+		attributes = append(attributes, &AttributeInfo{BaseType: String, SempName: "queueName", TerraformName: "queue_name", Identifying: true, Required: true, RequiresReplace: true,
+			Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}})
+	}
+	return EntityInputs{
+		TerraformName: terraformName,
+		ObjectType:    StandardObject,
+		PathTemplate:  pathTemplate,
+		Attributes:    attributes,
+	}
+}
+
+func newMsgVpnTestResource(t *testing.T, defaultMsgVpnName string) *brokerResource {
+	t.Helper()
+	config := fakeBrokerProviderConfig("http://localhost:8080", true)
+	if defaultMsgVpnName != "" {
+		config["default_msg_vpn_name"] = tftypes.NewValue(tftypes.String, defaultMsgVpnName)
+	}
+	r := brokerResource(newBrokerResource(msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")))
+	r.client = configureTestProvider(t, config)
+	return &r
+}
+
+func (r *brokerResource) msgVpnTestValue(ctx context.Context, msgVpnName tftypes.Value) tftypes.Value {
+	return tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+		"msg_vpn_name": msgVpnName,
+		"queue_name":   tftypes.NewValue(tftypes.String, "q1"),
+	})
+}
+
+func TestOptionalMsgVpnName(t *testing.T) {
+	queue := newBrokerResource(msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}"))
+	attr := queue.schema.Attributes["msg_vpn_name"].(schema.StringAttribute)
+	if attr.Required || !attr.Optional || !attr.Computed {
+		t.Errorf("expected msg_vpn_name of an object within a Message VPN to be optional and computed")
+	}
+	if len(attr.PlanModifiers) != 2 {
+		t.Errorf("expected msg_vpn_name to keep its state and require replacement, got %v plan modifiers", len(attr.PlanModifiers))
+	}
+	msgVpn := newBrokerResource(msgVpnTestInputs("msg_vpn_test", "/msgVpns/{msgVpnName}"))
+	if !msgVpn.schema.Attributes["msg_vpn_name"].IsRequired() {
+		t.Errorf("expected msg_vpn_name of a Message VPN to be required")
+	}
+}
+
+func TestDefaultMsgVpnNamePlan(t *testing.T) {
+	ctx := context.Background()
+	null := tftypes.NewValue(tftypes.String, nil)
+	unknown := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	tests := []struct {
+		name            string
+		defaultName     string
+		configured      tftypes.Value
+		planned         tftypes.Value
+		state           string
+		want            string
+		requiresReplace bool
+		wantError       bool
+	}{
+		{name: "create", defaultName: "vpn1", configured: null, planned: unknown, want: "vpn1"},
+		{name: "configured", defaultName: "vpn1", configured: tftypes.NewValue(tftypes.String, "vpn2"), planned: tftypes.NewValue(tftypes.String, "vpn2"), want: "vpn2"},
+		{name: "unchanged", defaultName: "vpn1", configured: null, planned: tftypes.NewValue(tftypes.String, "vpn1"), state: "vpn1", want: "vpn1"},
+		{name: "changed default", defaultName: "vpn2", configured: null, planned: tftypes.NewValue(tftypes.String, "vpn1"), state: "vpn1", want: "vpn2", requiresReplace: true},
+		{name: "no default", configured: null, planned: unknown, wantError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newMsgVpnTestResource(t, test.defaultName)
+			stateValue := tftypes.NewValue(r.schema.Type().TerraformType(ctx), nil)
+			if test.state != "" {
+				stateValue = r.msgVpnTestValue(ctx, tftypes.NewValue(tftypes.String, test.state))
+			}
+			request := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: r.schema, Raw: r.msgVpnTestValue(ctx, test.configured)},
+				Plan:   tfsdk.Plan{Schema: r.schema, Raw: r.msgVpnTestValue(ctx, test.planned)},
+				State:  tfsdk.State{Schema: r.schema, Raw: stateValue},
+			}
+			response := &resource.ModifyPlanResponse{Plan: request.Plan}
+			r.ModifyPlan(ctx, request, response)
+
+			if response.Diagnostics.HasError() != test.wantError {
+				t.Fatalf("unexpected diagnostics %v", response.Diagnostics)
+			}
+			if test.wantError {
+				return
+			}
+			var got types.String
+			response.Plan.GetAttribute(ctx, path.Root("msg_vpn_name"), &got)
+			if got.ValueString() != test.want {
+				t.Errorf("planned msg_vpn_name %v, want %v", got, test.want)
+			}
+			if (len(response.RequiresReplace) != 0) != test.requiresReplace {
+				t.Errorf("unexpected replacement %v", response.RequiresReplace)
+			}
+			if !request.Config.Raw.Equal(r.msgVpnTestValue(ctx, test.configured)) {
+				t.Errorf("expected the configuration to be left unchanged, got %v", request.Config.Raw)
+			}
+		})
+	}
+}
+
+func TestDefaultMsgVpnNameImport(t *testing.T) {
+	ctx := context.Background()
+	for _, id := range []string{"q1", "vpn1/q1"} {
+		t.Run(id, func(t *testing.T) {
+			r := newMsgVpnTestResource(t, "vpn1")
+			response := &resource.ImportStateResponse{State: tfsdk.State{Schema: r.schema}}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: id}, response)
+			if response.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics %v", response.Diagnostics)
+			}
+			if !response.State.Raw.Equal(r.msgVpnTestValue(ctx, tftypes.NewValue(tftypes.String, "vpn1"))) {
+				t.Errorf("unexpected state %v", response.State.Raw)
+			}
+		})
+	}
+	r := newMsgVpnTestResource(t, "")
+	response := &resource.ImportStateResponse{State: tfsdk.State{Schema: r.schema}}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: "q1"}, response)
+	if !response.Diagnostics.HasError() {
+		t.Errorf("expected an error importing without a Message VPN or a default")
+	}
+}
+
+func TestDefaultMsgVpnNameDataSource(t *testing.T) {
+	ctx := context.Background()
+	ds := brokerDataSource(newBrokerDataSource(msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")))
+	if ds.schema.Attributes["msg_vpn_name"].IsRequired() {
+		t.Errorf("expected msg_vpn_name of the data source to be optional")
+	}
+	config := tftypes.NewValue(ds.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+		"msg_vpn_name": tftypes.NewValue(tftypes.String, nil),
+		"queue_name":   tftypes.NewValue(tftypes.String, "q1"),
+	})
+	if _, err := ds.withDefaultMsgVpnName(config); err != ErrMissingMsgVpnName {
+		t.Errorf("expected %v, got %v", ErrMissingMsgVpnName, err)
+	}
+	ds.client = &brokerClient{defaultMsgVpnName: "vpn1"}
+	config, err := ds.withDefaultMsgVpnName(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sempPath, err := resolveSempPath(ds.pathTemplate, ds.identifyingAttributes, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sempPath != "/msgVpns/vpn1/queues/q1" {
+		t.Errorf("unexpected SEMP path %v", sempPath)
+	}
+}
@@ -0,0 +1,146 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// opaqueFingerprints is the private state key of the fingerprints of the sensitive attributes read in opaque form
+const opaqueFingerprints = "opaque_fingerprints"
+
+// minOpaquePasswordLength and maxOpaquePasswordLength are the length limits of the SEMP opaquePassword parameter
+const (
+	minOpaquePasswordLength = 8
+	maxOpaquePasswordLength = 128
+)
+
+// checkOpaquePassword checks opaque_password, which SEMP only accepts over TLS
+func checkOpaquePassword(opaquePassword string, urls []string) error {
+	if len(opaquePassword) < minOpaquePasswordLength || len(opaquePassword) > maxOpaquePasswordLength {
+		return fmt.Errorf("opaque_password must have %d to %d characters", minOpaquePasswordLength, maxOpaquePasswordLength)
+	}
+	for _, u := range urls {
+		if !strings.HasPrefix(strings.ToLower(u), "https://") {
+			return fmt.Errorf("opaque_password requires TLS transport, the broker url %v must use https", u)
+		}
+	}
+	return nil
+}
+
+// withOpaquePassword adds the opaquePassword query parameter to a SEMP path, so that the broker returns the
+// sensitive attributes in an opaque form encrypted with the password
+func withOpaquePassword(sempPath string, opaquePassword string) string {
+	return sempPath + "?opaquePassword=" + url.QueryEscape(opaquePassword)
+}
+
+// sensitiveFingerprints removes the opaque values of the sensitive attributes from a SEMP response, so that they are
+// never stored in the state, and returns their fingerprints by attribute name
+func (r *brokerResource) sensitiveFingerprints(sempData map[string]any) map[string]string {
+	fingerprints := map[string]string{}
+	for _, attr := range r.attributes {
+		if !attr.Sensitive || attr.WriteOnly || attr.SempName == "" {
+			continue
+		}
+		v, ok := sempData[attr.SempName].(string)
+		delete(sempData, attr.SempName)
+		if !ok {
+			continue
+		}
+		sum := sha256.Sum256([]byte(v))
+		fingerprints[attr.TerraformName] = hex.EncodeToString(sum[:])
+	}
+	return fingerprints
+}
+
+// unstableFingerprint marks a sensitive attribute whose opaque value differed between two reads of the unchanged
+// object, its changes on the broker can't be detected
+const unstableFingerprint = ""
+
+// needsSecondRead reports whether a sensitive attribute has no fingerprint of an earlier refresh. SEMP does not document
+// whether the opaque form of an attribute is encrypted deterministically, and this has not been verified against a
+// broker, so the object is read twice before the fingerprint of such an attribute is used to detect changes.
+func needsSecondRead(previous map[string]string, current map[string]string) bool {
+	for name := range current {
+		if _, ok := previous[name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stableFingerprints returns the fingerprints to compare in the next refresh. The fingerprint of an attribute without
+// one of an earlier refresh is only kept if a second read of the object returned the same, otherwise the attribute
+// is marked as unstable and left out of the drift detection from now on.
+func stableFingerprints(previous map[string]string, current map[string]string, second map[string]string) (map[string]string, []string) {
+	stable := map[string]string{}
+	var unstable []string
+	for name, fingerprint := range current {
+		previousFingerprint, ok := previous[name]
+		switch {
+		case ok && previousFingerprint == unstableFingerprint:
+			stable[name] = unstableFingerprint
+		case ok || second[name] == fingerprint:
+			stable[name] = fingerprint
+		default:
+			stable[name] = unstableFingerprint
+			unstable = append(unstable, name)
+		}
+	}
+	sort.Strings(unstable)
+	return stable, unstable
+}
+
+// withSensitiveDrift marks the sensitive attributes whose fingerprint changed since the last refresh as changed on the
+// broker, so that their configured value is planned to be sent again: the attribute is set to null in the state, or
+// the version of its write-only argument if that is used instead. Attributes that are not configured are left out.
+// It returns the new state and the names of the drifted attributes.
+func (r *brokerResource) withSensitiveDrift(state tftypes.Value, previous map[string]string, current map[string]string) (tftypes.Value, []string, error) {
+	stateValues, err := copyObjectValues(state)
+	if err != nil {
+		return state, nil, err
+	}
+	var drifted []string
+	for _, attr := range r.attributes {
+		name := attr.TerraformName
+		fingerprint, ok := previous[name]
+		if !ok || fingerprint == unstableFingerprint || fingerprint == current[name] {
+			continue
+		}
+		version := name + writeOnlyVersionSuffix
+		switch {
+		case stateValues[name].IsKnown() && !stateValues[name].IsNull():
+			stateValues[name] = tftypes.NewValue(attr.TerraformType, nil)
+		case stateValues[version].IsKnown() && !stateValues[version].IsNull():
+			stateValues[version] = tftypes.NewValue(tftypes.Number, nil)
+		default:
+			continue
+		}
+		drifted = append(drifted, name)
+	}
+	if len(drifted) == 0 {
+		return state, nil, nil
+	}
+	return tftypes.NewValue(state.Type(), stateValues), drifted, nil
+}
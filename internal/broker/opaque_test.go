@@ -0,0 +1,205 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCheckOpaquePassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		opaquePassword string
+		urls           []string
+		wantErr        bool
+	}{
+		{name: "https", opaquePassword: "opaque-secret", urls: []string{"https://broker1:1943", "HTTPS://broker2:1943"}},
+		{name: "http", opaquePassword: "opaque-secret", urls: []string{"https://broker1:1943", "http://broker2:8080"}, wantErr: true},
+		{name: "too short", opaquePassword: "short", urls: []string{"https://broker1:1943"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkOpaquePassword(test.opaquePassword, test.urls); (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestSensitiveDrift(t *testing.T) {
+	r := brokerResource(newBrokerResource(writeOnlyTestInputs()))
+	ctx := context.Background()
+	value := func(password tftypes.Value, version tftypes.Value) tftypes.Value {
+		return tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+			"msg_vpn_name":        tftypes.NewValue(tftypes.String, "default"),
+			"queue_name":          tftypes.NewValue(tftypes.String, "q1"),
+			"password":            password,
+			"password_wo":         tftypes.NewValue(tftypes.String, nil),
+			"password_wo_version": version,
+		})
+	}
+	secret := tftypes.NewValue(tftypes.String, "secret")
+	nullString := tftypes.NewValue(tftypes.String, nil)
+	version := tftypes.NewValue(tftypes.Number, 1)
+	nullNumber := tftypes.NewValue(tftypes.Number, nil)
+
+	sempData := map[string]any{"msgVpnName": "default", "queueName": "q1", "password": "opaque1"}
+	fingerprints := r.sensitiveFingerprints(sempData)
+	if _, ok := sempData["password"]; ok {
+		t.Error("expected the opaque password to be removed from the response")
+	}
+	changed := r.sensitiveFingerprints(map[string]any{"password": "opaque2"})
+	if fingerprints["password"] == "" || fingerprints["password"] == changed["password"] {
+		t.Fatalf("expected different fingerprints, got %v and %v", fingerprints, changed)
+	}
+
+	tests := []struct {
+		name        string
+		state       tftypes.Value
+		previous    map[string]string
+		want        tftypes.Value
+		wantDrifted []string
+	}{
+		{name: "first refresh", state: value(secret, nullNumber), want: value(secret, nullNumber)},
+		{name: "unchanged", state: value(secret, nullNumber), previous: fingerprints, want: value(secret, nullNumber)},
+		{name: "changed", state: value(secret, nullNumber), previous: changed, want: value(nullString, nullNumber), wantDrifted: []string{"password"}},
+		{name: "changed write-only", state: value(nullString, version), previous: changed, want: value(nullString, nullNumber), wantDrifted: []string{"password"}},
+		{name: "not configured", state: value(nullString, nullNumber), previous: changed, want: value(nullString, nullNumber)},
+		{name: "unstable", state: value(secret, nullNumber), previous: map[string]string{"password": unstableFingerprint}, want: value(secret, nullNumber)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, drifted, err := r.withSensitiveDrift(test.state, test.previous, fingerprints)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("got state %v, want %v", got, test.want)
+			}
+			if !reflect.DeepEqual(drifted, test.wantDrifted) {
+				t.Errorf("got drifted %v, want %v", drifted, test.wantDrifted)
+			}
+		})
+	}
+}
+
+func TestReadWithOpaquePassword(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"data":{"msgVpnName":"default","queueName":"q1","password":"opaque1"},"meta":{"responseCode":200}}`))
+	}))
+	t.Cleanup(server.Close)
+	config := fakeBrokerProviderConfig(server.URL, true)
+	config["insecure_skip_verify"] = tftypes.NewValue(tftypes.Bool, true)
+	config["opaque_password"] = tftypes.NewValue(tftypes.String, "opaque-secret")
+	r := brokerResource(newBrokerResource(writeOnlyTestInputs()))
+	r.client = configureTestProvider(t, config)
+	state := tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+		"msg_vpn_name":        tftypes.NewValue(tftypes.String, "default"),
+		"queue_name":          tftypes.NewValue(tftypes.String, "q1"),
+		"password":            tftypes.NewValue(tftypes.String, "secret"),
+		"password_wo":         tftypes.NewValue(tftypes.String, nil),
+		"password_wo_version": tftypes.NewValue(tftypes.Number, nil),
+	})
+
+	response := &resource.ReadResponse{State: tfsdk.State{Schema: r.schema}}
+	r.Read(ctx, resource.ReadRequest{State: tfsdk.State{Schema: r.schema, Raw: state}}, response)
+	if response.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics %v", response.Diagnostics)
+	}
+	if query != "opaquePassword=opaque-secret" {
+		t.Errorf("expected the opaque password to be sent, got query %v", query)
+	}
+	if !response.State.Raw.Equal(state) {
+		t.Errorf("expected the opaque password not to be stored, got %v", response.State.Raw)
+	}
+}
+
+func TestReadUnchangedObjectWithOpaquePassword(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name string
+		// opaque returns the opaque form of the password for the nth read
+		opaque       func(n int) string
+		wantDrifted  []bool
+		wantUnstable bool
+	}{
+		// deterministic encryption, the password is changed on the broker before the third refresh
+		{name: "deterministic", opaque: func(n int) string {
+			if n < 4 {
+				return "opaque1"
+			}
+			return "opaque2"
+		}, wantDrifted: []bool{false, false, true}},
+		// encryption with a random salt, a new opaque form for every read
+		{name: "randomized", opaque: func(n int) string { return fmt.Sprintf("opaque%d", n) },
+			wantDrifted: []bool{false, false, false}, wantUnstable: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var reads atomic.Int64
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprintf(w, `{"data":{"msgVpnName":"default","queueName":"q1","password":%q},"meta":{"responseCode":200}}`,
+					test.opaque(int(reads.Add(1))))
+			}))
+			t.Cleanup(server.Close)
+			config := fakeBrokerProviderConfig(server.URL, true)
+			config["insecure_skip_verify"] = tftypes.NewValue(tftypes.Bool, true)
+			config["opaque_password"] = tftypes.NewValue(tftypes.String, "opaque-secret")
+			r := brokerResource(newBrokerResource(writeOnlyTestInputs()))
+			r.client = configureTestProvider(t, config)
+			state := tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+				"msg_vpn_name":        tftypes.NewValue(tftypes.String, "default"),
+				"queue_name":          tftypes.NewValue(tftypes.String, "q1"),
+				"password":            tftypes.NewValue(tftypes.String, "secret"),
+				"password_wo":         tftypes.NewValue(tftypes.String, nil),
+				"password_wo_version": tftypes.NewValue(tftypes.Number, nil),
+			})
+			// the private state of the resource, kept between refreshes as Terraform does, its type is internal to the
+			// framework
+			var holder resource.ReadResponse
+			reflect.ValueOf(&holder).Elem().FieldByName("Private").Set(reflect.New(reflect.TypeOf(holder.Private).Elem()))
+			private := holder.Private
+			for i, wantDrifted := range test.wantDrifted {
+				response := &resource.ReadResponse{State: tfsdk.State{Schema: r.schema}, Private: private}
+				r.Read(ctx, resource.ReadRequest{State: tfsdk.State{Schema: r.schema, Raw: state}, Private: private}, response)
+				if response.Diagnostics.HasError() {
+					t.Fatalf("unexpected diagnostics %v", response.Diagnostics)
+				}
+				if drifted := len(response.Diagnostics.Warnings()) != 0; drifted != wantDrifted {
+					t.Errorf("refresh %d: got drift %v, want %v", i+1, response.Diagnostics, wantDrifted)
+				}
+			}
+			fingerprints, _ := private.GetKey(ctx, opaqueFingerprints)
+			if unstable := strings.Contains(string(fingerprints), `"password":""`); unstable != test.wantUnstable {
+				t.Errorf("got fingerprints %s, want the password unstable %v", fingerprints, test.wantUnstable)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+
+	"terraform-provider-solacebroker/internal/semp"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startOperation prepares a Terraform operation on a resource or data source with the given configuration, plan or
+// state data. It starts the span the SEMP requests of the operation are recorded under, and makes the client mask the
+// values of the sensitive attributes in the data until the operation completes. The returned function must be called
+// when the operation completes.
+func (b *brokerEntityBase) startOperation(ctx context.Context, operation string, data tftypes.Value, diagnostics *diag.Diagnostics) (context.Context, func()) {
+	resourceType := providerTypeName + "_" + b.terraformName
+	ctx, span := otel.Tracer(semp.TracerName).Start(ctx, operation+" "+resourceType, trace.WithAttributes(
+		attribute.String("terraform.operation", operation),
+		attribute.String("terraform.resource_type", resourceType)))
+	if b.client == nil {
+		return ctx, func() { endOperationSpan(span, *diagnostics) }
+	}
+	removeSecrets := func() {}
+	if sempData, err := b.converter.FromTerraform(data); err == nil {
+		removeSecrets = b.client.AddOperationSecrets(sensitiveValues(b.attributes, sempData)...)
+	}
+	return b.client.MaskLogs(ctx), func() {
+		*diagnostics = redactDiagnostics(b.client.Client, *diagnostics)
+		removeSecrets()
+		endOperationSpan(span, *diagnostics)
+	}
+}
+
+// endOperationSpan ends the span of a Terraform operation, marking it as failed if an error was reported
+func endOperationSpan(span trace.Span, diagnostics diag.Diagnostics) {
+	if errs := diagnostics.Errors(); len(errs) > 0 {
+		span.SetStatus(codes.Error, errs[0].Summary())
+	}
+	span.End()
+}
+
+// sensitiveValues returns the string values of the sensitive attributes in SEMP data, including nested attributes
+func sensitiveValues(attributes []*AttributeInfo, sempData any) []string {
+	data, ok := sempData.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, attr := range attributes {
+		switch value := data[attr.SempName].(type) {
+		case string:
+			if attr.Sensitive {
+				values = append(values, value)
+			}
+		case map[string]any:
+			values = append(values, sensitiveValues(attr.Attributes, value)...)
+		}
+	}
+	return values
+}
+
+// redactDiagnostics masks secrets in the summary and detail of diagnostics
+func redactDiagnostics(client *semp.Client, diagnostics diag.Diagnostics) diag.Diagnostics {
+	var redacted diag.Diagnostics
+	for _, d := range diagnostics {
+		var r diag.Diagnostic
+		if d.Severity() == diag.SeverityError {
+			r = diag.NewErrorDiagnostic(client.Redact(d.Summary()), client.Redact(d.Detail()))
+		} else {
+			r = diag.NewWarningDiagnostic(client.Redact(d.Summary()), client.Redact(d.Detail()))
+		}
+		if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+			r = diag.WithPath(withPath.Path(), r)
+		}
+		redacted = append(redacted, r)
+	}
+	return redacted
+}
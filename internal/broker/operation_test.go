@@ -0,0 +1,196 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terraform-provider-solacebroker/internal/semp"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// testSecret is the value of a sensitive attribute whose name does not suggest a secret, so it can only be masked
+// by its value
+const testSecret = "S3cr3t-Header/Value"
+
+// testWriteOnlySecret is the value of a write-only argument, which is null in the plan and only set in the
+// configuration
+const testWriteOnlySecret = "Wr1te-Only/Passw0rd"
+
+func secretTestInputs() EntityInputs {
+	return EntityInputs{
+		TerraformName: "msg_vpn_secret_test",
+		ObjectType:    StandardObject,
+		PathTemplate:  "/msgVpns/{msgVpnName}/tests/{testName}",
+		Attributes: []*AttributeInfo{
+			{BaseType: String, SempName: "msgVpnName", TerraformName: "msg_vpn_name", Identifying: true, Required: true,
+				Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}},
+			{BaseType: String, SempName: "testName", TerraformName: "test_name", Identifying: true, Required: true,
+				Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}},
+			{BaseType: String, SempName: "headerValue", TerraformName: "header_value", Sensitive: true,
+				Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}},
+			{BaseType: String, SempName: "testPassword", TerraformName: "test_password", Sensitive: true,
+				Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}},
+		},
+	}
+}
+
+func newSecretTestResource(t *testing.T, handler http.HandlerFunc) *brokerResource {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	r := brokerResource(newBrokerResource(secretTestInputs()))
+	r.client = configureTestProvider(t, fakeBrokerProviderConfig(server.URL, true))
+	return &r
+}
+
+func (r *brokerResource) testPlan(ctx context.Context, name string) tfsdk.Plan {
+	objectType := r.schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	values["msg_vpn_name"] = tftypes.NewValue(tftypes.String, "default")
+	values["test_name"] = tftypes.NewValue(tftypes.String, name)
+	values["header_value"] = tftypes.NewValue(tftypes.String, testSecret)
+	values["test_password_wo_version"] = tftypes.NewValue(tftypes.Number, 1)
+	return tfsdk.Plan{Schema: r.schema, Raw: tftypes.NewValue(objectType, values)}
+}
+
+// testConfig returns the configuration of a test plan, which also has the write-only password
+func (r *brokerResource) testConfig(plan tfsdk.Plan) tfsdk.Config {
+	values, _ := copyObjectValues(plan.Raw)
+	values["test_password_wo"] = tftypes.NewValue(tftypes.String, testWriteOnlySecret)
+	return tfsdk.Config{Schema: r.schema, Raw: tftypes.NewValue(plan.Raw.Type(), values)}
+}
+
+func TestSensitiveValuesAreRedacted(t *testing.T) {
+	// the broker echoes the request in its errors, as it does for some invalid values
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.Contains(r.URL.Path, "/badRequest"):
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"meta":{"responseCode":400,"error":{"code":89,"description":"Invalid request ` +
+				strings.ReplaceAll(string(body), `"`, `'`) + `","status":"INVALID_PARAMETER"}}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("internal error processing " + string(body)))
+		}
+	}
+	for _, name := range []string{"badRequest", "internalError"} {
+		t.Run(name, func(t *testing.T) {
+			r := newSecretTestResource(t, echo)
+			var logs bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &logs)
+			response := &resource.CreateResponse{}
+			plan := r.testPlan(ctx, name)
+			r.Create(ctx, resource.CreateRequest{Plan: plan, Config: r.testConfig(plan)}, response)
+
+			if !response.Diagnostics.HasError() {
+				t.Fatal("expected the request to fail")
+			}
+			for _, d := range response.Diagnostics {
+				if strings.Contains(d.Summary()+d.Detail(), testSecret) || strings.Contains(d.Summary()+d.Detail(), testWriteOnlySecret) {
+					t.Errorf("diagnostic reveals a sensitive value: %v: %v", d.Summary(), d.Detail())
+				}
+			}
+			if !strings.Contains(response.Diagnostics.Errors()[0].Detail(), "<redacted>") {
+				t.Errorf("expected the sensitive value to be redacted, got %v", response.Diagnostics.Errors()[0].Detail())
+			}
+			if logs.Len() == 0 {
+				t.Fatal("expected log output")
+			}
+			for _, secret := range []string{testSecret, "S3cr3t-Header%2FValue", testWriteOnlySecret, "Wr1te-Only%2FPassw0rd"} {
+				if strings.Contains(logs.String(), secret) {
+					t.Errorf("log output reveals the sensitive value %v:\n%v", secret, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestOperationReportsStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	t.Setenv(semp.StatsFileEnv, path)
+	// the statistics of an earlier run are replaced
+	const staleStats = `[{"broker":"https://other:1943","method":"GET","path_template":"/msgVpns","count":1}]`
+	if err := os.WriteFile(path, []byte(staleStats), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"msgVpnName":"default","testName":"test"},"meta":{"responseCode":200}}`))
+	}))
+	defer server.Close()
+	p := &BrokerProvider{Version: "test"}
+	r := brokerResource(newBrokerResource(secretTestInputs()))
+	r.client = configureProvider(t, p, fakeBrokerProviderConfig(server.URL, true))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		response := &resource.CreateResponse{}
+		r.Create(ctx, resource.CreateRequest{Plan: r.testPlan(ctx, "test")}, response)
+		if response.Diagnostics.HasError() {
+			t.Fatal(response.Diagnostics)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != staleStats {
+		t.Fatalf("got %s, %v, want the file unchanged until the provider is closed", content, err)
+	}
+	p.Close(ctx)
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats []semp.EndpointStats
+	if err := json.Unmarshal(content, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Count != 2 || stats[0].Broker != r.client.statsBroker {
+		t.Errorf("got %s, want only the statistics of the 2 requests of this run", content)
+	}
+}
+
+func TestSensitiveValues(t *testing.T) {
+	attributes := []*AttributeInfo{
+		{SempName: "name"},
+		{SempName: "password", Sensitive: true},
+		{SempName: "settings", Attributes: []*AttributeInfo{{SempName: "key", Sensitive: true}}},
+	}
+	got := sensitiveValues(attributes, map[string]any{
+		"name":     "test",
+		"password": "secret1",
+		"settings": map[string]any{"key": "secret2"},
+	})
+	if strings.Join(got, ",") != "secret1,secret2" {
+		t.Errorf("got %v, want secret1 and secret2", got)
+	}
+}
@@ -0,0 +1,323 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var ErrUnsupportedPlatform = errors.New("not supported by the broker platform")
+
+// The SEMP specs of several broker platforms may be registered. The variants of an object type are registered with
+// the same Terraform name and are merged into one resource and data source, see newPlatformEntity.
+var (
+	resourceVariants   = map[string][]EntityInputs{}
+	dataSourceVariants = map[string][]EntityInputs{}
+	resourceIndex      = map[string]int{}
+	dataSourceIndex    = map[string]int{}
+)
+
+// platformOf returns the platform of an object type, an empty platform is the default platform of SempDetail
+func platformOf(platform string) string {
+	if platform == "" {
+		return SempDetail.Platform
+	}
+	return platform
+}
+
+// SupportedPlatforms returns the sorted broker platforms whose SEMP specs are registered
+func SupportedPlatforms() []string {
+	var platforms []string
+	for platform := range SempDetails {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// checkSupportedPlatform returns an error if no SEMP spec of a broker platform is registered
+func checkSupportedPlatform(platform string) error {
+	if _, ok := SempDetails[platform]; ok {
+		return nil
+	}
+	var names []string
+	for _, p := range SupportedPlatforms() {
+		names = append(names, BrokerPlatformName[p])
+	}
+	return fmt.Errorf("broker platform \"%s\" does not match provider supported platform: %s", BrokerPlatformName[platform], strings.Join(names, ", "))
+}
+
+// PlatformSempDetail returns the SEMP spec of a broker platform, the empty platform is the default one
+func PlatformSempDetail(platform string) SempVersionDetail {
+	return SempDetails[platformOf(platform)]
+}
+
+// PlatformEntities returns the object types of a broker platform, the empty platform is the default one
+func PlatformEntities(platform string) []EntityInputs {
+	platform = platformOf(platform)
+	var entities []EntityInputs
+	for _, e := range Entities {
+		if platformOf(e.Platform) == platform {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// forPlatform returns the variant of the object type of a broker platform, the empty platform is the default one
+func (b *brokerEntityBase) forPlatform(platform string) (brokerEntityBase, error) {
+	platform = platformOf(platform)
+	variants := b.platformVariants
+	if variants == nil {
+		variants = []*brokerEntityBase{b}
+	}
+	for _, v := range variants {
+		if platformOf(v.platform) == platform {
+			variant := *v
+			variant.client = b.client
+			return variant, nil
+		}
+	}
+	return brokerEntityBase{}, fmt.Errorf("%w: %v_%v is not available on the %v platform", ErrUnsupportedPlatform, providerTypeName, b.terraformName, BrokerPlatformName[platform])
+}
+
+// onBrokerPlatform returns the resource with the variant of its object type of the broker platform
+func (r *brokerResource) onBrokerPlatform() (*brokerResource, error) {
+	base, err := r.forPlatform(r.client.platform)
+	if err != nil {
+		return nil, err
+	}
+	return &brokerResource{schema: r.schema, brokerEntityBase: base}, nil
+}
+
+// onBrokerPlatform returns the data source with the variant of its object type of the broker platform
+func (ds *brokerDataSource) onBrokerPlatform() (*brokerDataSource, error) {
+	base, err := ds.forPlatform(ds.client.platform)
+	if err != nil {
+		return nil, err
+	}
+	return &brokerDataSource{schema: ds.schema, brokerEntityBase: base}, nil
+}
+
+// newPlatformEntity merges the platform variants of an object type. The schema has the attributes of all variants,
+// validators that differ between variants are applied per platform at plan time instead, see checkPlatformPlan.
+// Each variant converts the attributes of the merged schema, those it does not support are always null.
+func newPlatformEntity(variants []EntityInputs, isResource bool) brokerEntity[schema.Schema] {
+	var attributeLists [][]*AttributeInfo
+	for _, v := range variants {
+		attributeLists = append(attributeLists, v.Attributes)
+	}
+	merged := variants[0]
+	merged.Attributes = mergeAttributes(attributeLists)
+	entity := newBrokerEntity(merged, isResource)
+	for _, v := range variants {
+		inputs := v
+		inputs.Attributes = variantAttributes(v.Attributes, merged.Attributes)
+		variant := newBrokerEntity(inputs, isResource).brokerEntityBase
+		own := map[string]*AttributeInfo{}
+		for _, attr := range v.Attributes {
+			own[attr.TerraformName] = attr
+		}
+		for _, attr := range merged.Attributes {
+			if own[attr.TerraformName] == nil {
+				variant.unsupportedAttributes = append(variant.unsupportedAttributes, attr.TerraformName)
+				if isResource && hasWriteOnlyArgument(attr) {
+					variant.unsupportedAttributes = append(variant.unsupportedAttributes, attr.TerraformName+writeOnlySuffix, attr.TerraformName+writeOnlyVersionSuffix)
+				}
+			}
+		}
+		variant.platformValidatedAttributes = platformValidatedAttributes(path.Empty(), v.Attributes, merged.Attributes)
+		entity.platformVariants = append(entity.platformVariants, &variant)
+	}
+	return entity
+}
+
+// mergeAttributes returns the union of the attributes of the variants of an object type, in the order they are
+// first found. The validators of attributes are dropped if they differ between variants.
+func mergeAttributes(attributeLists [][]*AttributeInfo) []*AttributeInfo {
+	var merged []*AttributeInfo
+	index := map[string]*AttributeInfo{}
+	nested := map[string][][]*AttributeInfo{}
+	for _, attributes := range attributeLists {
+		for _, attr := range attributes {
+			if attr.Attributes != nil {
+				nested[attr.TerraformName] = append(nested[attr.TerraformName], attr.Attributes)
+			}
+			m, ok := index[attr.TerraformName]
+			if !ok {
+				c := *attr
+				index[attr.TerraformName] = &c
+				merged = append(merged, &c)
+				continue
+			}
+			if validatorsDescription(m) != validatorsDescription(attr) {
+				m.StringValidators = nil
+				m.Int64Validators = nil
+				m.BoolValidators = nil
+			}
+		}
+	}
+	for _, m := range merged {
+		if m.Attributes != nil {
+			m.Attributes = mergeAttributes(nested[m.TerraformName])
+		}
+	}
+	return merged
+}
+
+// variantAttributes returns the attributes of a variant in the shape of the merged attributes, attributes the
+// variant does not have are taken from the merged attributes
+func variantAttributes(attributes []*AttributeInfo, merged []*AttributeInfo) []*AttributeInfo {
+	own := map[string]*AttributeInfo{}
+	for _, attr := range attributes {
+		own[attr.TerraformName] = attr
+	}
+	var result []*AttributeInfo
+	for _, m := range merged {
+		attr, ok := own[m.TerraformName]
+		if !ok {
+			result = append(result, m)
+			continue
+		}
+		c := *attr
+		if c.Attributes != nil {
+			c.Attributes = variantAttributes(c.Attributes, m.Attributes)
+		}
+		result = append(result, &c)
+	}
+	return result
+}
+
+// platformValidatedAttribute is an attribute of a platform variant whose validators are not in the merged schema
+type platformValidatedAttribute struct {
+	path path.Path
+	attr *AttributeInfo
+}
+
+// platformValidatedAttributes returns the attributes of a variant below parent, including nested attributes, whose
+// validators were dropped from the merged attributes as they differ between the variants
+func platformValidatedAttributes(parent path.Path, attributes []*AttributeInfo, merged []*AttributeInfo) []platformValidatedAttribute {
+	own := map[string]*AttributeInfo{}
+	for _, attr := range attributes {
+		own[attr.TerraformName] = attr
+	}
+	var result []platformValidatedAttribute
+	for _, m := range merged {
+		attr, ok := own[m.TerraformName]
+		switch {
+		case !ok:
+		case attr.Attributes != nil:
+			result = append(result, platformValidatedAttributes(parent.AtName(m.TerraformName), attr.Attributes, m.Attributes)...)
+		case !hasValidators(m) && hasValidators(attr):
+			result = append(result, platformValidatedAttribute{path: parent.AtName(m.TerraformName), attr: attr})
+		}
+	}
+	return result
+}
+
+func hasValidators(attr *AttributeInfo) bool {
+	return len(attr.StringValidators) != 0 || len(attr.Int64Validators) != 0 || len(attr.BoolValidators) != 0
+}
+
+// validatorsDescription identifies the validators of an attribute, validators with the same description are the same
+func validatorsDescription(attr *AttributeInfo) string {
+	ctx := context.Background()
+	var descriptions []string
+	for _, v := range attr.StringValidators {
+		descriptions = append(descriptions, v.Description(ctx))
+	}
+	for _, v := range attr.Int64Validators {
+		descriptions = append(descriptions, v.Description(ctx))
+	}
+	for _, v := range attr.BoolValidators {
+		descriptions = append(descriptions, v.Description(ctx))
+	}
+	return strings.Join(descriptions, "\n")
+}
+
+// checkPlatformPlan checks a plan against the variant of the object type of the broker platform, if the SEMP specs
+// of several platforms are registered. The broker platform is read at plan time for this.
+func (r *brokerResource) checkPlatformPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	if len(SempDetails) < 2 || request.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+	if err := checkBrokerRequirements(ctx, r.client); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
+	variant, err := r.forPlatform(r.client.platform)
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Unsupported resource", err)
+		return
+	}
+	configured := map[string]tftypes.Value{}
+	if err := request.Config.Raw.As(&configured); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Error converting data", err)
+		return
+	}
+	for _, name := range variant.unsupportedAttributes {
+		if v, ok := configured[name]; ok && !v.IsNull() {
+			response.Diagnostics.AddAttributeError(path.Root(name), "Unsupported attribute",
+				fmt.Sprintf("%v is not available on the %v platform", name, BrokerPlatformName[platformOf(r.client.platform)]))
+		}
+	}
+	for _, validated := range variant.platformValidatedAttributes {
+		validateAttribute(ctx, validated.path, validated.attr, request.Config, response)
+	}
+}
+
+// validateAttribute applies the validators of the attribute at path p to its configured value
+func validateAttribute(ctx context.Context, p path.Path, attr *AttributeInfo, config tfsdk.Config, response *resource.ModifyPlanResponse) {
+	switch attr.BaseType {
+	case String:
+		var v types.String
+		response.Diagnostics.Append(config.GetAttribute(ctx, p, &v)...)
+		for _, val := range attr.StringValidators {
+			validatorResponse := &validator.StringResponse{}
+			val.ValidateString(ctx, validator.StringRequest{Path: p, PathExpression: p.Expression(), Config: config, ConfigValue: v}, validatorResponse)
+			response.Diagnostics.Append(validatorResponse.Diagnostics...)
+		}
+	case Int64:
+		var v types.Int64
+		response.Diagnostics.Append(config.GetAttribute(ctx, p, &v)...)
+		for _, val := range attr.Int64Validators {
+			validatorResponse := &validator.Int64Response{}
+			val.ValidateInt64(ctx, validator.Int64Request{Path: p, PathExpression: p.Expression(), Config: config, ConfigValue: v}, validatorResponse)
+			response.Diagnostics.Append(validatorResponse.Diagnostics...)
+		}
+	case Bool:
+		var v types.Bool
+		response.Diagnostics.Append(config.GetAttribute(ctx, p, &v)...)
+		for _, val := range attr.BoolValidators {
+			validatorResponse := &validator.BoolResponse{}
+			val.ValidateBool(ctx, validator.BoolRequest{Path: p, PathExpression: p.Expression(), Config: config, ConfigValue: v}, validatorResponse)
+			response.Diagnostics.Append(validatorResponse.Diagnostics...)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestMain(m *testing.M) {
+	// the generated SEMP specs are not registered in this package
+	RegisterSempVersionDetails("/SEMP/v2/config", "2.49", "VMR")
+	os.Exit(m.Run())
+}
+
+// platformTestVariants returns the Software Event Broker and Appliance variants of a queue, which differ in the
+// maximum message size and have an attribute of their own each
+func platformTestVariants() []EntityInputs {
+	maxMsgSize := func(maximum int64) *AttributeInfo {
+		return &AttributeInfo{BaseType: Int64, SempName: "maxMsgSize", TerraformName: "max_msg_size",
+			Type: types.Int64Type, TerraformType: tftypes.Number, Converter: IntegerConverter{},
+			Int64Validators: []validator.Int64{int64validator.Between(0, maximum)}}
+	}
+	vmr := msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+	vmr.Attributes = append(vmr.Attributes, maxMsgSize(10000000), &AttributeInfo{BaseType: String, SempName: "vmrOnly", TerraformName: "vmr_only",
+		Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}})
+	appliance := msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+	appliance.Platform = "Appliance"
+	appliance.Attributes = append(appliance.Attributes, maxMsgSize(30000000), &AttributeInfo{BaseType: Bool, SempName: "applianceOnly", TerraformName: "appliance_only",
+		Type: types.BoolType, TerraformType: tftypes.Bool, Converter: SimpleConverter[bool]{TerraformType: tftypes.Bool}})
+	return []EntityInputs{vmr, appliance}
+}
+
+func TestPlatformVariants(t *testing.T) {
+	ctx := context.Background()
+	e := newPlatformEntity(platformTestVariants(), true)
+	for _, name := range []string{"msg_vpn_name", "queue_name", "max_msg_size", "vmr_only", "appliance_only"} {
+		if _, ok := e.schema.Attributes[name]; !ok {
+			t.Errorf("expected attribute %v in the merged schema", name)
+		}
+	}
+	if validators := e.schema.Attributes["max_msg_size"].(schema.Int64Attribute).Validators; len(validators) != 0 {
+		t.Errorf("expected the differing validators of max_msg_size to be left out of the schema, got %v", validators)
+	}
+
+	appliance, err := e.forPlatform("Appliance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(appliance.unsupportedAttributes, []string{"vmr_only"}) {
+		t.Errorf("unexpected unsupported attributes %v", appliance.unsupportedAttributes)
+	}
+	if len(appliance.platformValidatedAttributes) != 1 || !appliance.platformValidatedAttributes[0].path.Equal(path.Root("max_msg_size")) {
+		t.Errorf("expected max_msg_size to be validated per platform, got %v", appliance.platformValidatedAttributes)
+	}
+	v, err := appliance.converter.ToTerraform(map[string]any{"msgVpnName": "vpn1", "queueName": "q1", "maxMsgSize": 20000000, "applianceOnly": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Type().Equal(e.schema.Type().TerraformType(ctx)) {
+		t.Errorf("expected the variant to convert to the merged schema type, got %v", v.Type())
+	}
+
+	vmr, err := e.forPlatform("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vmr.unsupportedAttributes, []string{"appliance_only"}) {
+		t.Errorf("unexpected unsupported attributes %v", vmr.unsupportedAttributes)
+	}
+	if _, err := e.forPlatform("Other"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedPlatform, err)
+	}
+	single := newBrokerResource(msgVpnTestInputs("msg_vpn_test", "/msgVpns/{msgVpnName}"))
+	if _, err := single.forPlatform("Appliance"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("expected %v for an object type of the default platform only, got %v", ErrUnsupportedPlatform, err)
+	}
+}
+
+func TestPlatformPlan(t *testing.T) {
+	SempDetails["Appliance"] = SempVersionDetail{BasePath: "/SEMP/v2/config", SempVersion: "2.49", Platform: "Appliance"}
+	t.Cleanup(func() { delete(SempDetails, "Appliance") })
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		platform   string
+		maxMsgSize int64
+		vmrOnly    string
+		wantError  bool
+	}{
+		{name: "appliance", platform: "Appliance", maxMsgSize: 20000000},
+		{name: "appliance attribute not supported", platform: "Appliance", maxMsgSize: 100, vmrOnly: "x", wantError: true},
+		{name: "software broker", platform: "VMR", maxMsgSize: 100, vmrOnly: "x"},
+		{name: "software broker validator", platform: "VMR", maxMsgSize: 20000000, wantError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := brokerResource(newPlatformEntity(platformTestVariants(), true))
+			r.client = &brokerClient{skipApiCheck: true, platform: test.platform, defaultMsgVpnName: "vpn1"}
+			vmrOnly := tftypes.NewValue(tftypes.String, nil)
+			if test.vmrOnly != "" {
+				vmrOnly = tftypes.NewValue(tftypes.String, test.vmrOnly)
+			}
+			config := tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+				"msg_vpn_name":   tftypes.NewValue(tftypes.String, "vpn1"),
+				"queue_name":     tftypes.NewValue(tftypes.String, "q1"),
+				"max_msg_size":   tftypes.NewValue(tftypes.Number, test.maxMsgSize),
+				"vmr_only":       vmrOnly,
+				"appliance_only": tftypes.NewValue(tftypes.Bool, nil),
+			})
+			request := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: r.schema, Raw: config},
+				Plan:   tfsdk.Plan{Schema: r.schema, Raw: config},
+				State:  tfsdk.State{Schema: r.schema, Raw: tftypes.NewValue(r.schema.Type().TerraformType(ctx), nil)},
+			}
+			response := &resource.ModifyPlanResponse{Plan: request.Plan}
+			r.ModifyPlan(ctx, request, response)
+			if response.Diagnostics.HasError() != test.wantError {
+				t.Errorf("unexpected diagnostics %v", response.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestPlatformPlanNestedAttribute(t *testing.T) {
+	SempDetails["Appliance"] = SempVersionDetail{BasePath: "/SEMP/v2/config", SempVersion: "2.49", Platform: "Appliance"}
+	t.Cleanup(func() { delete(SempDetails, "Appliance") })
+	ctx := context.Background()
+	// the variants differ in the maximum of a nested attribute
+	threshold := func(maximum int64) *AttributeInfo {
+		return &AttributeInfo{BaseType: Struct, SempName: "eventThreshold", TerraformName: "event_threshold",
+			Attributes: []*AttributeInfo{{BaseType: Int64, SempName: "setPercent", TerraformName: "set_percent",
+				Type: types.Int64Type, TerraformType: tftypes.Number, Converter: IntegerConverter{},
+				Int64Validators: []validator.Int64{int64validator.Between(0, maximum)}}}}
+	}
+	vmr := msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+	vmr.Attributes = append(vmr.Attributes, threshold(50))
+	appliance := msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+	appliance.Platform = "Appliance"
+	appliance.Attributes = append(appliance.Attributes, threshold(100))
+	tests := []struct {
+		name       string
+		platform   string
+		setPercent any
+		wantError  bool
+	}{
+		{name: "appliance", platform: "Appliance", setPercent: 80},
+		{name: "software broker", platform: "VMR", setPercent: 40},
+		{name: "software broker validator", platform: "VMR", setPercent: 80, wantError: true},
+		{name: "not configured", platform: "VMR"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := brokerResource(newPlatformEntity([]EntityInputs{vmr, appliance}, true))
+			r.client = &brokerClient{skipApiCheck: true, platform: test.platform, defaultMsgVpnName: "vpn1"}
+			objectType := r.schema.Type().TerraformType(ctx).(tftypes.Object)
+			thresholdType := objectType.AttributeTypes["event_threshold"].(tftypes.Object)
+			thresholdValue := tftypes.NewValue(thresholdType, nil)
+			if test.setPercent != nil {
+				thresholdValue = tftypes.NewValue(thresholdType, map[string]tftypes.Value{
+					"set_percent": tftypes.NewValue(tftypes.Number, test.setPercent),
+				})
+			}
+			config := tftypes.NewValue(objectType, map[string]tftypes.Value{
+				"msg_vpn_name":    tftypes.NewValue(tftypes.String, "vpn1"),
+				"queue_name":      tftypes.NewValue(tftypes.String, "q1"),
+				"event_threshold": thresholdValue,
+			})
+			request := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: r.schema, Raw: config},
+				Plan:   tfsdk.Plan{Schema: r.schema, Raw: config},
+				State:  tfsdk.State{Schema: r.schema, Raw: tftypes.NewValue(objectType, nil)},
+			}
+			response := &resource.ModifyPlanResponse{Plan: request.Plan}
+			r.ModifyPlan(ctx, request, response)
+			if response.Diagnostics.HasError() != test.wantError {
+				t.Errorf("unexpected diagnostics %v", response.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestPlatformFromBroker(t *testing.T) {
+	SempDetails["Appliance"] = SempVersionDetail{BasePath: "/SEMP/v2/config", SempVersion: "2.49", Platform: "Appliance"}
+	t.Cleanup(func() { delete(SempDetails, "Appliance") })
+	ctx := context.Background()
+	b := newFakeBroker(t, "Appliance")
+	r := brokerResource(newPlatformEntity(platformTestVariants(), true))
+	r.client = configureTestProvider(t, fakeBrokerProviderConfig(b.URL, false))
+	plan := func(maxMsgSize int64, vmrOnly string) *resource.ModifyPlanResponse {
+		vmrOnlyValue := tftypes.NewValue(tftypes.String, nil)
+		if vmrOnly != "" {
+			vmrOnlyValue = tftypes.NewValue(tftypes.String, vmrOnly)
+		}
+		config := tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+			"msg_vpn_name":   tftypes.NewValue(tftypes.String, "default"),
+			"queue_name":     tftypes.NewValue(tftypes.String, "q1"),
+			"max_msg_size":   tftypes.NewValue(tftypes.Number, maxMsgSize),
+			"vmr_only":       vmrOnlyValue,
+			"appliance_only": tftypes.NewValue(tftypes.Bool, true),
+		})
+		request := resource.ModifyPlanRequest{
+			Config: tfsdk.Config{Schema: r.schema, Raw: config},
+			Plan:   tfsdk.Plan{Schema: r.schema, Raw: config},
+			State:  tfsdk.State{Schema: r.schema, Raw: tftypes.NewValue(r.schema.Type().TerraformType(ctx), nil)},
+		}
+		response := &resource.ModifyPlanResponse{Plan: request.Plan}
+		r.ModifyPlan(ctx, request, response)
+		return response
+	}
+
+	// above the maximum of the Software Event Broker variant, within the one of the Appliance variant
+	if response := plan(20000000, ""); response.Diagnostics.HasError() {
+		t.Errorf("unexpected diagnostics %v", response.Diagnostics)
+	}
+	if r.client.platform != "Appliance" {
+		t.Errorf("expected the platform read from /about/api, got %q", r.client.platform)
+	}
+	if response := plan(40000000, ""); !response.Diagnostics.HasError() {
+		t.Error("expected the validator of the Appliance variant to reject max_msg_size")
+	}
+	if response := plan(100, "x"); !response.Diagnostics.HasError() {
+		t.Error("expected vmr_only to be rejected on the Appliance")
+	}
+	variant, err := r.onBrokerPlatform()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(variant.unsupportedAttributes, []string{"vmr_only"}) {
+		t.Errorf("expected the Appliance variant, got unsupported attributes %v", variant.unsupportedAttributes)
+	}
+}
@@ -19,52 +19,129 @@ package broker
 import (
 	"context"
 	"fmt"
-	"strings"
+	"log"
+	"sync"
+	"terraform-provider-solacebroker/internal/semp"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-var _ provider.Provider = &BrokerProvider{}
+var (
+	_ provider.Provider              = &BrokerProvider{}
+	_ provider.ProviderWithFunctions = &BrokerProvider{}
+)
 var ProviderVersion string
 
+// providerTypeName is the prefix of the resource and data source type names
+const providerTypeName = "solacebroker"
+
 type BrokerProvider struct {
 	Version string
+	// clients are the clients of the provider configurations, they are closed when the provider process exits
+	clients     []*brokerClient
+	clientsLock sync.Mutex
 }
 
 func (p *BrokerProvider) Metadata(_ context.Context, _ provider.MetadataRequest, response *provider.MetadataResponse) {
 	response.Version = p.Version
-	response.TypeName = "solacebroker"
+	response.TypeName = providerTypeName
 }
 
 func (p *BrokerProvider) Schema(_ context.Context, _ provider.SchemaRequest, response *provider.SchemaResponse) {
 	response.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"url": schema.StringAttribute{
-				MarkdownDescription: "The base URL of the event broker, for example `https://mybroker.example.org:<semp-service-port>/`. The trailing / can be omitted.",
-				Required:            true,
+				MarkdownDescription: "The base URL of the event broker, for example `https://mybroker.example.org:<semp-service-port>/`. The trailing / can be omitted. Either url or urls must be set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("urls")),
+				},
+			},
+			"urls": schema.ListAttribute{
+				MarkdownDescription: "The base URLs of the nodes of an HA redundancy group, for example `[\"https://primary.example.org:1943\", \"https://backup.example.org:1943\"]`. The nodes are probed when the provider is configured and requests are sent to the active node, they fail over to its mate if the active node cannot be reached or turns out to be standby. Nodes whose redundancy role cannot be read are not failed over to. When set through the environment, separate URLs by commas. Conflicts with url.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
 			},
 			"username": schema.StringAttribute{
-				MarkdownDescription: "The username to connect to the broker with.  Requires password and conflicts with bearer_token.",
+				MarkdownDescription: "The username to connect to the broker with.  Requires password and conflicts with bearer_token and client_certificate.",
 				Optional:            true,
 			},
 			"password": schema.StringAttribute{
-				MarkdownDescription: "The password to connect to the broker with. Requires username and conflicts with bearer_token.",
+				MarkdownDescription: "The password to connect to the broker with. Requires username and conflicts with bearer_token and client_certificate.",
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"password_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file holding the password to connect to the broker with. The file is read when the provider is configured, so it can hold a short-lived secret. Conflicts with password.",
+				Optional:            true,
+			},
 			"bearer_token": schema.StringAttribute{
-				MarkdownDescription: "A bearer token that will be sent in the Authorization header of SEMP requests. Requires TLS transport enabled. Conflicts with username and password.",
+				MarkdownDescription: "A bearer token that will be sent in the Authorization header of SEMP requests. Requires TLS transport enabled. Conflicts with username, password and client_certificate.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"bearer_token_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file holding the bearer token to send in the Authorization header of SEMP requests. The file is read when the provider is configured, so it can hold a short-lived token. Conflicts with bearer_token.",
+				Optional:            true,
+			},
+			"credential_process": schema.StringAttribute{
+				MarkdownDescription: "A command that is run through the shell when the provider is configured and prints the credentials to connect to the broker with to standard output, as a JSON object with either `username` and `password` or `bearer_token`. Conflicts with username, password and bearer_token.",
+				Optional:            true,
+			},
+			"client_certificate": schema.StringAttribute{
+				MarkdownDescription: "The client certificate to authenticate to the broker with, as PEM content or the path of a PEM file. It may include the certificate chain. Requires client_private_key and TLS transport enabled. Conflicts with username, password and bearer_token.",
+				Optional:            true,
+			},
+			"client_private_key": schema.StringAttribute{
+				MarkdownDescription: "The private key of the client certificate, as PEM content or the path of a PEM file. Requires client_certificate.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"client_private_key_password": schema.StringAttribute{
+				MarkdownDescription: "The password to decrypt client_private_key if it is encrypted. Both PKCS#8 and legacy PEM encryption are supported.",
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"oauth_token_url": schema.StringAttribute{
+				MarkdownDescription: "The token endpoint of the OAuth authorization server. If set, access tokens are requested using the OAuth client credentials grant, cached, refreshed before they expire, and sent in the Authorization header of SEMP requests. Requires oauth_client_id, oauth_client_secret and TLS transport enabled. Conflicts with username, password, bearer_token and client_certificate.",
+				Optional:            true,
+			},
+			"oauth_client_id": schema.StringAttribute{
+				MarkdownDescription: "The OAuth client ID to request access tokens with. Requires oauth_token_url.",
+				Optional:            true,
+			},
+			"oauth_client_secret": schema.StringAttribute{
+				MarkdownDescription: "The OAuth client secret to request access tokens with. Requires oauth_token_url.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"oauth_scopes": schema.ListAttribute{
+				MarkdownDescription: "The scopes to request access tokens for. When set through the environment, separate scopes by commas or spaces.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"session_login": schema.BoolAttribute{
+				MarkdownDescription: "Log in to the broker once with username and password and authenticate later SEMP requests with the session cookie of the broker instead of basic authentication. The session is renewed when it expires and ended when the provider process exits. This reduces the authentication load on brokers that authenticate management users with RADIUS or LDAP. Requires username and password. The default value is false.",
+				Optional:            true,
+			},
 			"retries": schema.Int64Attribute{
-				MarkdownDescription: "The number of retries for a SEMP call. The default value is 10.",
+				MarkdownDescription: "The number of retries for a SEMP call. Calls are retried when the broker is temporarily unavailable (HTTP status 429, 502, 503 or 504) or the connection fails, a POST is only retried if the broker cannot have processed it yet. The default value is 10.",
 				Optional:            true,
 			},
 			"retry_min_interval": schema.StringAttribute{
@@ -80,13 +157,83 @@ func (p *BrokerProvider) Schema(_ context.Context, _ provider.SchemaRequest, res
 				Optional:            true,
 			},
 			"request_min_interval": schema.StringAttribute{
-				MarkdownDescription: "A [duration](https://pkg.go.dev/maze.io/x/duration#ParseDuration) string indicating the minimum interval between requests; this serves as a rate limit. This setting does not apply to retries. Set to 0 for no rate limit. The default value is 100ms (which equates to a rate limit of 10 calls per second).",
+				MarkdownDescription: "A [duration](https://pkg.go.dev/maze.io/x/duration#ParseDuration) string indicating the minimum interval between requests; this serves as a rate limit. This setting does not apply to retries. The rate is reduced automatically while the broker reports that it is overloaded (HTTP status 429 or 503) or responds unusually slowly, and restored as the broker recovers. Set to 0 for no rate limit. The default value is 100ms (which equates to a rate limit of 10 calls per second).",
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of SEMP requests in flight at the same time, which also sizes the connection pool to the broker. Lower it to protect the SEMP service of a small broker, raise it to make full use of a large one. The default value is 10.",
+				Optional:            true,
+			},
+			"request_burst_size": schema.Int64Attribute{
+				MarkdownDescription: "The number of requests that may be sent in a row before request_min_interval applies. The default value is 1.",
 				Optional:            true,
 			},
 			"insecure_skip_verify": schema.BoolAttribute{
 				MarkdownDescription: "Disable validation of server SSL certificates, accept/ignore self-signed. The default value is false.",
 				Optional:            true,
 			},
+			"ca_certificate": schema.StringAttribute{
+				MarkdownDescription: "A bundle of one or more trusted CA certificates to validate the broker SEMP server certificate with, as PEM content or the path of a PEM file. If set, it replaces the system trust store. Use it instead of insecure_skip_verify for brokers with private-CA or self-signed certificates.",
+				Optional:            true,
+			},
+			"tls_server_name": schema.StringAttribute{
+				MarkdownDescription: "The server name to use for SNI and to validate the broker SEMP server certificate with, if different from the host in url. For example when the broker is reached through a load balancer with a different hostname.",
+				Optional:            true,
+			},
+			"tls_min_version": schema.StringAttribute{
+				MarkdownDescription: "The minimum TLS version accepted for the connection to the broker, one of `1.0`, `1.1`, `1.2` or `1.3`. The default value is 1.2.",
+				Optional:            true,
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the proxy to send SEMP requests through, for example `http://proxy.example.org:3128`. If set, it replaces the proxy set by the `HTTP_PROXY` and `HTTPS_PROXY` environment variables.",
+				Optional:            true,
+			},
+			"no_proxy": schema.StringAttribute{
+				MarkdownDescription: "A comma-separated list of hosts, domains and networks that are reached without a proxy. If set, it replaces the `NO_PROXY` environment variable.",
+				Optional:            true,
+			},
+			"request_headers": schema.MapAttribute{
+				MarkdownDescription: "Additional HTTP headers to send with every SEMP request, for example a tenant ID or an API gateway key. When set through the environment, separate `name=value` pairs by commas.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"redact_patterns": schema.ListAttribute{
+				MarkdownDescription: "Regular expressions matching additional text to mask in log output, error messages and recorded SEMP traffic, for example confidential object names. Credentials, Authorization headers and the values of sensitive attributes are always masked. When set through the environment, separate the expressions by newlines.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"host_header": schema.StringAttribute{
+				MarkdownDescription: "The Host header to send with every SEMP request, if different from the host in url. For example when the broker is reached through an API gateway that routes by host name. It is also used as the TLS server name unless tls_server_name is set.",
+				Optional:            true,
+			},
+			"default_msg_vpn_name": schema.StringAttribute{
+				MarkdownDescription: "The Message VPN of the resources and data sources within a Message VPN that leave `msg_vpn_name` unset. It allows one module to be instantiated per Message VPN through provider aliases. Changing it replaces the resources that use it.",
+				Optional:            true,
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Refuse all SEMP requests that could change the broker, so that plans and refreshes can run with credentials that are allowed to make changes without any chance of a change. Applying changes fails in read-only mode. The default value is false.",
+				Optional:            true,
+			},
+			"allowed_msg_vpns": schema.ListAttribute{
+				MarkdownDescription: "The Message VPNs whose objects resources may change, names may contain `*` and `?` wildcards. If set, resources cannot change objects in other Message VPNs or objects that are not within a Message VPN. When set through the environment, separate names by commas or spaces.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"denied_msg_vpns": schema.ListAttribute{
+				MarkdownDescription: "The Message VPNs whose objects resources may not change, names may contain `*` and `?` wildcards. It takes precedence over allowed_msg_vpns. When set through the environment, separate names by commas or spaces.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"broker_version_constraint": schema.StringAttribute{
+				MarkdownDescription: "A constraint on the broker version, for example `>= 10.6, < 11`, checked before the first change or refresh. The constraint is matched against the broker firmware version, such as 10.6.1.52, not the SEMP API version. The broker version is read through the legacy SEMP v1 API; if the API is not available to the user, the constraint is not checked and a warning is logged. Not checked if skip_api_check is set.",
+				Optional:            true,
+			},
+			"opaque_password": schema.StringAttribute{
+				MarkdownDescription: "A password of 8 to 128 characters to read the sensitive attributes of broker objects, such as passwords, in an opaque form encrypted with it when refreshing resources. The fingerprints of the opaque values are kept in the private state of the resources to detect changes made outside of Terraform, which are then planned to be set again. Requires TLS transport enabled.",
+				Optional:            true,
+				Sensitive:           true,
+			},
 			"skip_api_check": schema.BoolAttribute{
 				MarkdownDescription: "Disable validation of the broker SEMP API for supported platform and minimum version. The default value is false.",
 				Optional:            true,
@@ -119,7 +266,6 @@ func (p *BrokerProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		return
 	}
 	// Validate the provider configuration
-	ctx = tflog.SetField(ctx, "solacebroker_url", strings.Trim(config.Url.String(), "\""))
 	ctx = tflog.SetField(ctx, "solacebroker_provider_version", p.Version)
 	tflog.Debug(ctx, "Creating SEMP client")
 	client, d := client(&config)
@@ -129,10 +275,39 @@ func (p *BrokerProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			return
 		}
 	}
+	// the active node of a redundancy group is probed once, later requests only probe the nodes to fail over
+	client.SelectActiveNode(ctx)
+	ctx = tflog.SetField(ctx, "solacebroker_url", client.ActiveURL())
 	tflog.Info(ctx, "Solacebroker provider client config success")
+	p.clientsLock.Lock()
+	p.clients = append(p.clients, client)
+	p.clientsLock.Unlock()
 	resp.ResourceData = client
 	resp.DataSourceData = client
-	forceBrokerRequirementsCheck()
+}
+
+// Close ends the broker sessions of the provider configurations and reports their SEMP request statistics. It is
+// called once when the provider process exits, after Terraform has stopped the provider server.
+func (p *BrokerProvider) Close(ctx context.Context) {
+	p.clientsLock.Lock()
+	defer p.clientsLock.Unlock()
+	var allStats []semp.EndpointStats
+	for _, client := range p.clients {
+		client.Logout(ctx)
+		stats := client.Stats()
+		if len(stats) == 0 {
+			continue
+		}
+		log.Printf("[INFO] SEMP request statistics of the provider for %v:\n%v", client.statsBroker, semp.FormatStats(stats))
+		for _, s := range stats {
+			s.Broker = client.statsBroker
+			allStats = append(allStats, s)
+		}
+	}
+	if err := semp.WriteStatsFile(allStats); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+	p.clients = nil
 }
 
 func (p *BrokerProvider) Resources(_ context.Context) []func() resource.Resource {
@@ -143,18 +318,71 @@ func (p *BrokerProvider) DataSources(_ context.Context) []func() datasource.Data
 	return DataSources
 }
 
+func (p *BrokerProvider) Functions(_ context.Context) []func() function.Function {
+	return functions
+}
+
+// brokerClient is shared by the resources and data sources of one configured provider instance, so that
+// provider aliases targeting different brokers keep their own state
+type brokerClient struct {
+	*semp.Client
+	skipApiCheck      bool
+	defaultMsgVpnName string
+	msgVpnGuard       msgVpnGuard
+	// opaquePassword is empty unless sensitive attributes are read in opaque form to detect their drift
+	opaquePassword string
+	// brokerVersionConstraint is nil if no constraint is set
+	brokerVersionConstraint version.Constraints
+	// platform and sempVersion are read from the broker when its API is checked, the platform is empty for the
+	// default platform and the SEMP API version nil until then
+	sempVersion       *version.Version
+	platform          string
+	apiAlreadyChecked bool
+	lock              sync.Mutex
+	// statsBroker identifies the provider configuration in the statistics file
+	statsBroker string
+}
+
 type providerData struct {
-	Url                    types.String `tfsdk:"url"`
-	Username               types.String `tfsdk:"username"`
-	Password               types.String `tfsdk:"password"`
-	BearerToken            types.String `tfsdk:"bearer_token"`
-	Retries                types.Int64  `tfsdk:"retries"`
-	RetryMinInterval       types.String `tfsdk:"retry_min_interval"`
-	RetryMaxInterval       types.String `tfsdk:"retry_max_interval"`
-	RequestTimeoutDuration types.String `tfsdk:"request_timeout_duration"`
-	RequestMinInterval     types.String `tfsdk:"request_min_interval"`
-	InsecureSkipVerify     types.Bool   `tfsdk:"insecure_skip_verify"`
-	SkipApiCheck           types.Bool   `tfsdk:"skip_api_check"`
+	Url                      types.String `tfsdk:"url"`
+	Urls                     types.List   `tfsdk:"urls"`
+	Username                 types.String `tfsdk:"username"`
+	Password                 types.String `tfsdk:"password"`
+	PasswordFile             types.String `tfsdk:"password_file"`
+	BearerToken              types.String `tfsdk:"bearer_token"`
+	BearerTokenFile          types.String `tfsdk:"bearer_token_file"`
+	CredentialProcess        types.String `tfsdk:"credential_process"`
+	ClientCertificate        types.String `tfsdk:"client_certificate"`
+	ClientPrivateKey         types.String `tfsdk:"client_private_key"`
+	ClientPrivateKeyPassword types.String `tfsdk:"client_private_key_password"`
+	OAuthTokenURL            types.String `tfsdk:"oauth_token_url"`
+	OAuthClientID            types.String `tfsdk:"oauth_client_id"`
+	OAuthClientSecret        types.String `tfsdk:"oauth_client_secret"`
+	OAuthScopes              types.List   `tfsdk:"oauth_scopes"`
+	SessionLogin             types.Bool   `tfsdk:"session_login"`
+	Retries                  types.Int64  `tfsdk:"retries"`
+	RetryMinInterval         types.String `tfsdk:"retry_min_interval"`
+	RetryMaxInterval         types.String `tfsdk:"retry_max_interval"`
+	RequestTimeoutDuration   types.String `tfsdk:"request_timeout_duration"`
+	RequestMinInterval       types.String `tfsdk:"request_min_interval"`
+	RequestBurstSize         types.Int64  `tfsdk:"request_burst_size"`
+	MaxConcurrentRequests    types.Int64  `tfsdk:"max_concurrent_requests"`
+	InsecureSkipVerify       types.Bool   `tfsdk:"insecure_skip_verify"`
+	CACertificate            types.String `tfsdk:"ca_certificate"`
+	TLSServerName            types.String `tfsdk:"tls_server_name"`
+	TLSMinVersion            types.String `tfsdk:"tls_min_version"`
+	ProxyURL                 types.String `tfsdk:"proxy_url"`
+	NoProxy                  types.String `tfsdk:"no_proxy"`
+	RequestHeaders           types.Map    `tfsdk:"request_headers"`
+	HostHeader               types.String `tfsdk:"host_header"`
+	RedactPatterns           types.List   `tfsdk:"redact_patterns"`
+	DefaultMsgVpnName        types.String `tfsdk:"default_msg_vpn_name"`
+	ReadOnly                 types.Bool   `tfsdk:"read_only"`
+	AllowedMsgVpns           types.List   `tfsdk:"allowed_msg_vpns"`
+	DeniedMsgVpns            types.List   `tfsdk:"denied_msg_vpns"`
+	BrokerVersionConstraint  types.String `tfsdk:"broker_version_constraint"`
+	OpaquePassword           types.String `tfsdk:"opaque_password"`
+	SkipApiCheck             types.Bool   `tfsdk:"skip_api_check"`
 }
 
 func New(version string) func() provider.Provider {
@@ -0,0 +1,301 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+type fakeBroker struct {
+	*httptest.Server
+	platform string
+	// redundancyRole is reported by the monitor API, the role is unknown if it is empty
+	redundancyRole string
+	// v1Status is returned for SEMP v1 requests instead of the broker version, unless zero
+	v1Status      int
+	aboutRequests atomic.Int64
+	otherRequests atomic.Int64
+}
+
+func newFakeBroker(t *testing.T, platform string) *fakeBroker {
+	t.Helper()
+	b := &fakeBroker{platform: platform}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/SEMP" && b.v1Status != 0 {
+			w.WriteHeader(b.v1Status)
+			return
+		}
+		if r.URL.Path == "/SEMP" {
+			// SEMP v1 show version
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<rpc-reply><rpc><show><version><current-load>soltr_10.6.1.52</current-load></version></show></rpc><execute-result code="ok"/></rpc-reply>`))
+			return
+		}
+		if r.URL.Path == "/SEMP/v2/monitor/" && b.redundancyRole != "" {
+			_, _ = fmt.Fprintf(w, `{"data":{"redundancyRole":%q},"meta":{"responseCode":200}}`, b.redundancyRole)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/about/api") {
+			b.aboutRequests.Add(1)
+			_, _ = fmt.Fprintf(w, `{"data":{"platform":%q,"sempVersion":"2.40"},"meta":{"responseCode":200}}`, b.platform)
+			return
+		}
+		b.otherRequests.Add(1)
+		_, _ = w.Write([]byte(`{"data":{"msgVpnName":"default"},"meta":{"responseCode":200}}`))
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// configureTestProvider configures a new provider instance and returns the client its resources would use
+func configureTestProvider(t *testing.T, config map[string]tftypes.Value) *brokerClient {
+	t.Helper()
+	return configureProvider(t, &BrokerProvider{Version: "test"}, config)
+}
+
+// configureProvider configures a provider instance and returns the client its resources would use
+func configureProvider(t *testing.T, p *BrokerProvider, config map[string]tftypes.Value) *brokerClient {
+	t.Helper()
+	ctx := context.Background()
+	schemaResponse := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResponse)
+	attributeTypes := map[string]tftypes.Type{}
+	values := map[string]tftypes.Value{}
+	for name, attribute := range schemaResponse.Schema.Attributes {
+		attributeTypes[name] = attribute.GetType().TerraformType(ctx)
+		values[name] = tftypes.NewValue(attributeTypes[name], nil)
+	}
+	for name, value := range config {
+		values[name] = value
+	}
+	request := provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResponse.Schema,
+			Raw:    tftypes.NewValue(tftypes.Object{AttributeTypes: attributeTypes}, values),
+		},
+	}
+	response := &provider.ConfigureResponse{}
+	p.Configure(ctx, request, response)
+	if response.Diagnostics.HasError() {
+		t.Fatalf("provider configuration failed: %v", response.Diagnostics)
+	}
+	client, ok := response.ResourceData.(*brokerClient)
+	if !ok {
+		t.Fatalf("unexpected provider data %T", response.ResourceData)
+	}
+	return client
+}
+
+func fakeBrokerProviderConfig(url string, skipApiCheck bool) map[string]tftypes.Value {
+	return map[string]tftypes.Value{
+		"url":                  tftypes.NewValue(tftypes.String, url),
+		"username":             tftypes.NewValue(tftypes.String, "admin"),
+		"password":             tftypes.NewValue(tftypes.String, "admin"),
+		"request_min_interval": tftypes.NewValue(tftypes.String, "0s"),
+		"retries":              tftypes.NewValue(tftypes.Number, 0),
+		"skip_api_check":       tftypes.NewValue(tftypes.Bool, skipApiCheck),
+	}
+}
+
+func TestProviderAliasesKeepSeparateState(t *testing.T) {
+	// The primary broker would fail the platform check, so it must only pass because its alias skips the API check
+	primaryBroker := newFakeBroker(t, "Appliance")
+	drBroker := newFakeBroker(t, SempDetail.Platform)
+	primary := configureTestProvider(t, fakeBrokerProviderConfig(primaryBroker.URL, true))
+	dr := configureTestProvider(t, fakeBrokerProviderConfig(drBroker.URL, false))
+	otherBroker := newFakeBroker(t, SempDetail.Platform)
+	other := configureTestProvider(t, fakeBrokerProviderConfig(otherBroker.URL, false))
+
+	const requestsPerProvider = 20
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*requestsPerProvider+1)
+	for i := 0; i < requestsPerProvider; i++ {
+		for _, client := range []*brokerClient{primary, dr} {
+			wg.Add(1)
+			go func(client *brokerClient) {
+				defer wg.Done()
+				if err := checkBrokerRequirements(ctx, client); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/msgVpns/default"); err != nil {
+					errs <- err
+				}
+			}(client)
+		}
+	}
+	// Checking another alias in the meantime must not reset the broker check of the others
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := checkBrokerRequirements(ctx, other); err != nil {
+			errs <- err
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if n := primaryBroker.aboutRequests.Load(); n != 0 {
+		t.Errorf("expected no API check against the primary broker, got %d", n)
+	}
+	if n := drBroker.aboutRequests.Load(); n != 1 {
+		t.Errorf("expected exactly one API check against the DR broker, got %d", n)
+	}
+	if n := primaryBroker.otherRequests.Load(); n != requestsPerProvider {
+		t.Errorf("expected %d requests to the primary broker, got %d", requestsPerProvider, n)
+	}
+	if n := drBroker.otherRequests.Load(); n != requestsPerProvider {
+		t.Errorf("expected %d requests to the DR broker, got %d", requestsPerProvider, n)
+	}
+}
+
+func TestSessionLoginOnce(t *testing.T) {
+	var logins, logouts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/SEMP/v2/action/about/user/logout":
+			logouts.Add(1)
+			return
+		case r.URL.Path == "/SEMP/v2/config/about/user":
+			logins.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "Session", Value: "session", Path: "/"})
+		default:
+			if _, err := r.Cookie("Session"); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{"data":{"msgVpnName":"default","testName":"test"},"meta":{"responseCode":200}}`))
+	}))
+	defer server.Close()
+	config := fakeBrokerProviderConfig(server.URL, true)
+	config["session_login"] = tftypes.NewValue(tftypes.Bool, true)
+	p := &BrokerProvider{Version: "test"}
+	r := brokerResource(newBrokerResource(secretTestInputs()))
+	r.client = configureProvider(t, p, config)
+	ctx := context.Background()
+	for _, name := range []string{"a", "b", "c"} {
+		plan := r.testPlan(ctx, name)
+		createResponse := &resource.CreateResponse{}
+		r.Create(ctx, resource.CreateRequest{Plan: plan}, createResponse)
+		if createResponse.Diagnostics.HasError() {
+			t.Fatal(createResponse.Diagnostics)
+		}
+		deleteResponse := &resource.DeleteResponse{}
+		r.Delete(ctx, resource.DeleteRequest{State: tfsdk.State{Schema: plan.Schema, Raw: plan.Raw}}, deleteResponse)
+		if deleteResponse.Diagnostics.HasError() {
+			t.Fatal(deleteResponse.Diagnostics)
+		}
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("got %d logins, want 1", n)
+	}
+	if n := logouts.Load(); n != 0 {
+		t.Errorf("got %d logouts before the provider is closed, want 0", n)
+	}
+	p.Close(ctx)
+	if n := logouts.Load(); n != 1 {
+		t.Errorf("got %d logouts after the provider is closed, want 1", n)
+	}
+}
+
+func TestRedundancyGroupURLs(t *testing.T) {
+	primary := newFakeBroker(t, SempDetail.Platform)
+	primary.redundancyRole = "standby"
+	backup := newFakeBroker(t, SempDetail.Platform)
+	backup.redundancyRole = "active"
+	config := fakeBrokerProviderConfig("", true)
+	delete(config, "url")
+	config["urls"] = tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+		tftypes.NewValue(tftypes.String, primary.URL),
+		tftypes.NewValue(tftypes.String, backup.URL),
+	})
+	client := configureTestProvider(t, config)
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/msgVpns/default"); err != nil {
+		t.Fatal(err)
+	}
+	if n := primary.otherRequests.Load(); n != 0 {
+		t.Errorf("expected no requests to the standby primary, got %d", n)
+	}
+	if n := backup.otherRequests.Load(); n != 1 {
+		t.Errorf("expected the active backup to be selected when configuring, got %d requests", n)
+	}
+}
+
+func TestBrokerVersionConstraintWithoutSempV1(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusNotFound} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			b := newFakeBroker(t, SempDetail.Platform)
+			b.v1Status = status
+			config := fakeBrokerProviderConfig(b.URL, false)
+			config["broker_version_constraint"] = tftypes.NewValue(tftypes.String, ">= 10.7")
+			client := configureTestProvider(t, config)
+			var logs bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &logs)
+			if err := checkBrokerRequirements(ctx, client); err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !strings.Contains(logs.String(), `"@level":"warn","@message":"The SEMP v1 API is not available`) {
+				t.Errorf("expected a warning that the constraint is not checked, got logs:\n%v", logs.String())
+			}
+		})
+	}
+}
+
+func TestBrokerVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		wantErr    string
+	}{
+		{">= 10.6, < 11", ""},
+		{"~> 10.6.1", ""},
+		{">= 10.7", "broker version 10.6.1.52 does not meet broker_version_constraint \">= 10.7\""},
+	}
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			b := newFakeBroker(t, SempDetail.Platform)
+			config := fakeBrokerProviderConfig(b.URL, false)
+			config["broker_version_constraint"] = tftypes.NewValue(tftypes.String, test.constraint)
+			err := checkBrokerRequirements(context.Background(), configureTestProvider(t, config))
+			if test.wantErr == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if test.wantErr != "" && (err == nil || err.Error() != test.wantErr) {
+				t.Errorf("expected error %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
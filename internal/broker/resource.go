@@ -24,8 +24,8 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strings"
-	"sync"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -57,15 +57,10 @@ var (
 	_ resource.ResourceWithConfigure        = &brokerResource{}
 	_ resource.ResourceWithConfigValidators = &brokerResource{}
 	_ resource.ResourceWithImportState      = &brokerResource{}
+	_ resource.ResourceWithModifyPlan       = &brokerResource{}
 	_ resource.ResourceWithUpgradeState     = &brokerResource{}
 )
 
-var (
-	skipApiCheck      = false
-	apiAlreadyChecked = false
-	lock              sync.Mutex
-)
-
 func newBrokerResource(inputs EntityInputs) brokerEntity[schema.Schema] {
 	return newBrokerEntity(inputs, true)
 }
@@ -81,24 +76,20 @@ func newBrokerResourceClosure(templateEntity brokerEntity[schema.Schema]) func()
 	}
 }
 
-func forceBrokerRequirementsCheck() {
-	apiAlreadyChecked = false
-}
-
-func checkBrokerRequirements(ctx context.Context, client *semp.Client) error {
-	if !skipApiCheck && !apiAlreadyChecked {
-		lock.Lock()
-		defer lock.Unlock()
-		if apiAlreadyChecked {
-			return nil
-		}
+func checkBrokerRequirements(ctx context.Context, client *brokerClient) error {
+	if client.skipApiCheck {
+		return nil
+	}
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if !client.apiAlreadyChecked {
 		path := "/about/api"
 		result, err := client.RequestWithoutBody(ctx, http.MethodGet, path)
 		if err != nil {
 			return err
 		}
-		// To support broker developer versions ignore "+" in the returned version
-		brokerSempVersion, err := version.NewVersion(strings.Replace(result["sempVersion"].(string), "+", "", -1))
+		sempVersion, _ := result["sempVersion"].(string)
+		brokerSempVersion, err := ParseSempVersion(sempVersion)
 		if err != nil {
 			return err
 		}
@@ -106,11 +97,41 @@ func checkBrokerRequirements(ctx context.Context, client *semp.Client) error {
 		if brokerSempVersion.LessThan(minSempVersion) {
 			return fmt.Errorf("broker SEMP API version %s does not meet provider required minimum SEMP API version: %s", brokerSempVersion, minSempVersion)
 		}
-		brokerPlatform := result["platform"].(string)
-		if brokerPlatform != SempDetail.Platform {
-			return fmt.Errorf("broker platform \"%s\" does not match provider supported platform: %s", BrokerPlatformName[brokerPlatform], BrokerPlatformName[SempDetail.Platform])
+		brokerPlatform, _ := result["platform"].(string)
+		if err := checkSupportedPlatform(brokerPlatform); err != nil {
+			return err
+		}
+		client.platform = brokerPlatform
+		client.sempVersion = brokerSempVersion
+		if client.brokerVersionConstraint != nil {
+			if err := checkBrokerVersionConstraint(ctx, client); err != nil {
+				return err
+			}
 		}
-		apiAlreadyChecked = true
+		client.apiAlreadyChecked = true
+	}
+	return nil
+}
+
+// checkBrokerVersionConstraint checks the broker firmware version read through SEMP v1, such as 10.6.1.52, against
+// broker_version_constraint. The SEMP API version is no substitute for the firmware version, so the check is skipped
+// with a warning if the SEMP v1 API is not available to the user.
+func checkBrokerVersionConstraint(ctx context.Context, client *brokerClient) error {
+	v, err := client.BrokerVersion(ctx)
+	var sempErr *semp.Error
+	if errors.As(err, &sempErr) && slices.Contains([]int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}, sempErr.HTTPStatus) {
+		tflog.Warn(ctx, fmt.Sprintf("The SEMP v1 API is not available to read the broker version, broker_version_constraint \"%s\" is not checked: %v", client.brokerVersionConstraint, err))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the broker version to check broker_version_constraint \"%s\": %w", client.brokerVersionConstraint, err)
+	}
+	brokerVersion, err := version.NewVersion(v)
+	if err != nil {
+		return fmt.Errorf("unable to parse broker version %s: %w", v, err)
+	}
+	if !client.brokerVersionConstraint.Check(brokerVersion) {
+		return fmt.Errorf("broker version %s does not meet broker_version_constraint \"%s\"", v, client.brokerVersionConstraint)
 	}
 	return nil
 }
@@ -214,7 +235,7 @@ func (r *brokerResource) resetResponse(attributes []*AttributeInfo, response tft
 					responseValues[name] = state
 				} // else leave attr response unchanged
 			}
-		} else if stateExists && attr.Sensitive {
+		} else if stateExists && (attr.Sensitive || attr.WriteOnlyVersion) {
 			responseValues[name] = state
 		} else {
 			responseValues[name] = tftypes.NewValue(attr.TerraformType, nil)
@@ -265,7 +286,7 @@ func (r *brokerResource) Configure(_ context.Context, request resource.Configure
 	if request.ProviderData == nil {
 		return
 	}
-	client, ok := request.ProviderData.(*semp.Client)
+	client, ok := request.ProviderData.(*brokerClient)
 	if !ok {
 		response.Diagnostics.AddError(
 			"Unexpected resource configuration",
@@ -276,14 +297,43 @@ func (r *brokerResource) Configure(_ context.Context, request resource.Configure
 	r.client = client
 }
 
+func (r *brokerResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	r.checkPlatformPlan(ctx, request, response)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	r.checkSempVersionPlan(ctx, request, response)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	r.planDefaultMsgVpnName(ctx, request, response)
+}
+
 func (r *brokerResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	// the configuration has the values of the write-only arguments, which are null in the plan
+	ctx, finish := r.startOperation(ctx, "Create", request.Config.Raw, &response.Diagnostics)
+	defer finish()
 	client := r.client
 	if err := checkBrokerRequirements(ctx, client); err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
 		return
 	}
+	r, err := r.onBrokerPlatform()
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
+	if err := r.checkMsgVpnGuard(request.Plan.Raw); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Change not allowed", err)
+		return
+	}
 
-	sempData, err := r.converter.FromTerraform(request.Plan.Raw)
+	requestData, err := r.withWriteOnlyValues(request.Plan.Raw, request.Config.Raw, tftypes.NewValue(request.Plan.Raw.Type(), nil))
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Error converting data", err)
+		return
+	}
+	sempData, err := r.converter.FromTerraform(requestData)
 	if err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Error converting data", err)
 		return
@@ -334,17 +384,28 @@ func (r *brokerResource) Create(ctx context.Context, request resource.CreateRequ
 }
 
 func (r *brokerResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	ctx, finish := r.startOperation(ctx, "Read", request.State.Raw, &response.Diagnostics)
+	defer finish()
 	client := r.client
 	if err := checkBrokerRequirements(ctx, client); err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
 		return
 	}
+	r, err := r.onBrokerPlatform()
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
 	sempPath, err := resolveSempPath(r.pathTemplate, r.identifyingAttributes, request.State.Raw)
 	if err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Error generating SEMP path", err)
 		return
 	}
-	sempData, err := client.RequestWithoutBody(ctx, http.MethodGet, sempPath)
+	readPath := sempPath
+	if client.opaquePassword != "" {
+		readPath = withOpaquePassword(sempPath, client.opaquePassword)
+	}
+	sempData, err := client.RequestWithoutBody(ctx, http.MethodGet, readPath)
 	if err != nil {
 		if errors.Is(err, semp.ErrResourceNotFound) {
 			tflog.Info(ctx, fmt.Sprintf("Detected missing resource %v, removing from state", sempPath))
@@ -354,6 +415,7 @@ func (r *brokerResource) Read(ctx context.Context, request resource.ReadRequest,
 		}
 		return
 	}
+	fingerprints := r.sensitiveFingerprints(sempData)
 	responseData, err := r.converter.ToTerraform(sempData)
 	if err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "SEMP response conversion failed", err)
@@ -384,16 +446,79 @@ func (r *brokerResource) Read(ctx context.Context, request resource.ReadRequest,
 		addErrorToDiagnostics(&response.Diagnostics, "Read response postprocessing failed", err)
 		return
 	}
+	if client.opaquePassword != "" {
+		// Compare the fingerprints of the sensitive attributes with the ones of the last refresh to detect changes
+		//   made outside of Terraform
+		fingerprintsJson, diags := request.Private.GetKey(ctx, opaqueFingerprints)
+		if diags.HasError() {
+			response.Diagnostics.Append(diags...)
+			return
+		}
+		previousFingerprints := map[string]string{}
+		if fingerprintsJson != nil {
+			err = json.Unmarshal(fingerprintsJson, &previousFingerprints)
+			if err != nil {
+				addErrorToDiagnostics(&response.Diagnostics, "Retrieve of sensitive attribute fingerprints failed", err)
+				return
+			}
+		}
+		var second map[string]string
+		if needsSecondRead(previousFingerprints, fingerprints) {
+			secondData, err := client.RequestWithoutBody(ctx, http.MethodGet, readPath)
+			if err != nil {
+				addErrorToDiagnostics(&response.Diagnostics, "SEMP call failed", err)
+				return
+			}
+			second = r.sensitiveFingerprints(secondData)
+		}
+		var drifted, unstable []string
+		responseData, drifted, err = r.withSensitiveDrift(responseData, previousFingerprints, fingerprints)
+		if err != nil {
+			addErrorToDiagnostics(&response.Diagnostics, "Read response postprocessing failed", err)
+			return
+		}
+		if len(drifted) != 0 {
+			response.Diagnostics.AddWarning("Sensitive attributes changed outside of Terraform",
+				fmt.Sprintf("%v of %v changed on the broker, their configured values are planned to be set again", strings.Join(drifted, ", "), sempPath))
+		}
+		fingerprints, unstable = stableFingerprints(previousFingerprints, fingerprints, second)
+		if len(unstable) != 0 {
+			tflog.Warn(ctx, fmt.Sprintf("The opaque values of %v of %v differ between reads, their changes on the broker can't be detected", strings.Join(unstable, ", "), sempPath))
+		}
+		fingerprintsJson, err = json.Marshal(fingerprints)
+		if err != nil {
+			addErrorToDiagnostics(&response.Diagnostics, "Read response postprocessing failed", err)
+			return
+		}
+		response.Private.SetKey(ctx, opaqueFingerprints, fingerprintsJson)
+	}
 	response.State.Raw = responseData
 }
 
 func (r *brokerResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	// the configuration has the values of the write-only arguments, which are null in the plan
+	ctx, finish := r.startOperation(ctx, "Update", request.Config.Raw, &response.Diagnostics)
+	defer finish()
 	client := r.client
 	if err := checkBrokerRequirements(ctx, client); err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
 		return
 	}
-	sempData, err := r.converter.FromTerraform(request.Plan.Raw)
+	r, err := r.onBrokerPlatform()
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
+	if err := r.checkMsgVpnGuard(request.Plan.Raw); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Change not allowed", err)
+		return
+	}
+	requestData, err := r.withWriteOnlyValues(request.Plan.Raw, request.Config.Raw, request.State.Raw)
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Error converting data", err)
+		return
+	}
+	sempData, err := r.converter.FromTerraform(requestData)
 	if err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Error converting data", err)
 		return
@@ -431,16 +556,25 @@ func (r *brokerResource) Update(ctx context.Context, request resource.UpdateRequ
 	}
 	tflog.Info(ctx, fmt.Sprintf("Update: determined following broker-defined defaults:\n%v", brokerDefaultsData))
 	response.Private.SetKey(ctx, defaults, privatData)
+	// Sensitive attributes may have been changed, the next refresh takes their fingerprints again
+	response.Private.SetKey(ctx, opaqueFingerprints, nil)
 	// Set the response
 	response.State.Raw = request.Plan.Raw
 }
 
 func (r *brokerResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	ctx, finish := r.startOperation(ctx, "Delete", request.State.Raw, &response.Diagnostics)
+	defer finish()
 	client := r.client
 	if err := checkBrokerRequirements(ctx, client); err != nil {
 		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
 		return
 	}
+	r, err := r.onBrokerPlatform()
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
 	// don't actually do anything if the object is a singleton
 	if r.objectType == SingletonObject {
 		addWarningToDiagnostics(&response.Diagnostics, fmt.Sprintf("Associated state will be removed but singleton object %s cannot be deleted", r.terraformName), ErrDeleteSingletonOrDefaultsNotAllowed)
@@ -463,6 +597,10 @@ func (r *brokerResource) Delete(ctx context.Context, request resource.DeleteRequ
 			return
 		}
 	}
+	if err := r.checkMsgVpnGuard(request.State.Raw); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Change not allowed", err)
+		return
+	}
 	// request delete
 	_, err = client.RequestWithoutBody(ctx, http.MethodDelete, path)
 	if err != nil {
@@ -488,6 +626,15 @@ func (r *brokerResource) ImportState(_ context.Context, request resource.ImportS
 		return
 	}
 	split := strings.Split(strings.ReplaceAll(request.ID, ",", "/"), "/")
+	if inMsgVpn(r.pathTemplate) && r.defaultMsgVpnName() != "" {
+		// the Message VPN may be left out of the identifier if the provider has a default
+		if request.ID == "" {
+			split = nil
+		}
+		if len(split) == len(r.identifyingAttributes)-1 {
+			split = append([]string{url.PathEscape(r.defaultMsgVpnName())}, split...)
+		}
+	}
 	if len(split) != len(r.identifyingAttributes) {
 		r.addIdentifierErrorToDiagnostics(&response.Diagnostics, request.ID)
 		return
@@ -541,6 +688,7 @@ func (r *brokerResource) ConfigValidators(_ context.Context) []resource.ConfigVa
 func (r *brokerResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
 	schema := r.schema
 	converter := r.converter
+	attributes := r.attributes
 	version := getProviderMajorVersion(ProviderVersion)
 	upgraders := make(map[int64]resource.StateUpgrader)
 	// new code will add upgraders for each version, starting from 0
@@ -596,6 +744,16 @@ func (r *brokerResource) UpgradeState(ctx context.Context) map[int64]resource.St
 						}
 					}
 				}
+				// the versions of write-only attributes are not broker attributes, keep them
+				rawStateMap := map[string]tftypes.Value{}
+				if err := rawState.As(&rawStateMap); err == nil && resultsDataMap != nil {
+					for _, attr := range attributes {
+						if v, ok := rawStateMap[attr.TerraformName]; ok && attr.WriteOnlyVersion {
+							resultsDataMap[attr.TerraformName] = v
+						}
+					}
+					conversionResults = tftypes.NewValue(conversionResults.Type(), resultsDataMap)
+				}
 				resp.State.Raw = conversionResults
 			},
 		}
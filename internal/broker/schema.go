@@ -38,24 +38,80 @@ var DataSources []func() datasource.DataSource
 var Entities []EntityInputs
 
 func RegisterDataSource(inputs EntityInputs) {
+	inputs = withMinSempVersions(inputs)
+	name := inputs.TerraformName
+	dataSourceVariants[name] = append(dataSourceVariants[name], inputs)
+	if i, ok := dataSourceIndex[name]; ok {
+		// another platform variant of a registered object type
+		DataSources[i] = newBrokerDataSourceClosure(resourceEntityToDataSourceEntity(newPlatformEntity(dataSourceVariants[name], false)))
+		return
+	}
+	dataSourceIndex[name] = len(DataSources)
 	DataSources = append(DataSources, newBrokerDataSourceGenerator(inputs))
 }
 
 var Resources []func() resource.Resource
 
 func RegisterResource(inputs EntityInputs) {
-	Resources = append(Resources, newBrokerResourceGenerator(inputs))
+	inputs = withMinSempVersions(inputs)
 	Entities = append(Entities, inputs)
+	name := inputs.TerraformName
+	resourceVariants[name] = append(resourceVariants[name], inputs)
+	if i, ok := resourceIndex[name]; ok {
+		// another platform variant of a registered object type
+		Resources[i] = newBrokerResourceClosure(newPlatformEntity(resourceVariants[name], true))
+		return
+	}
+	resourceIndex[name] = len(Resources)
+	Resources = append(Resources, newBrokerResourceGenerator(inputs))
+}
+
+// PathTemplates returns the SEMP path templates of all broker objects
+func PathTemplates() []string {
+	var templates []string
+	for _, e := range Entities {
+		templates = append(templates, e.PathTemplate)
+		if e.PostPathTemplate != "" {
+			templates = append(templates, e.PostPathTemplate)
+		}
+	}
+	return templates
 }
 
+// SensitiveAttributeNames returns the SEMP names of the sensitive attributes of all broker objects
+func SensitiveAttributeNames() []string {
+	var names []string
+	var collect func(attributes []*AttributeInfo)
+	collect = func(attributes []*AttributeInfo) {
+		for _, attr := range attributes {
+			if attr.Sensitive {
+				names = append(names, attr.SempName)
+			}
+			collect(attr.Attributes)
+		}
+	}
+	for _, e := range Entities {
+		collect(e.Attributes)
+	}
+	return names
+}
+
+// SempDetail is the SEMP spec of the default platform, the first one registered
 var SempDetail SempVersionDetail
 
+// SempDetails holds the registered SEMP specs by platform
+var SempDetails = map[string]SempVersionDetail{}
+
 func RegisterSempVersionDetails(sempAPIBasePath string, sempVersion string, platform string) {
-	SempDetail = SempVersionDetail{
+	detail := SempVersionDetail{
 		BasePath:    sempAPIBasePath,
 		SempVersion: sempVersion,
 		Platform:    platform,
 	}
+	SempDetails[platform] = detail
+	if SempDetail.Platform == "" {
+		SempDetail = detail
+	}
 }
 
 func addObjectConverters(attributes []*AttributeInfo, isResource bool) {
@@ -88,7 +144,8 @@ func terraformAttributeMap(attributes []*AttributeInfo, isResource bool, require
 			// read-only attributes should only be in the datasource
 			continue
 		}
-		attrRequiresReplace := isResource && (requiresReplace || attr.RequiresReplace)
+		// write-only attributes are null in plans, so changes of them can't require replacement
+		attrRequiresReplace := isResource && (requiresReplace || attr.RequiresReplace) && !attr.WriteOnly
 		markdownDescription := attr.MarkdownDescription
 		if attrRequiresReplace && !attr.Identifying {
 			markdownDescription += " Note that this attribute requires replacement of the resource when updated."
@@ -115,6 +172,7 @@ func terraformAttributeMap(attributes []*AttributeInfo, isResource bool, require
 				Optional:            !attr.Required && isResource,
 				Computed:            !attr.Identifying && !isResource,
 				Sensitive:           attr.Sensitive,
+				WriteOnly:           attr.WriteOnly,
 				DeprecationMessage:  deprecationMessage,
 				Validators:          attr.StringValidators,
 				PlanModifiers:       modifiers[planmodifier.String](attrRequiresReplace, stringplanmodifier.RequiresReplace),
@@ -170,6 +228,10 @@ type EntityInputs struct {
 	PostPathTemplate    string
 	Version             int64
 	Attributes          []*AttributeInfo
+	// Platform is the broker platform of the SEMP spec of the object type, empty for the default platform
+	Platform string
+	// MinSempVersion is the SEMP API version the object type is available since, empty if it is not known
+	MinSempVersion string
 }
 
 func filterAttributesForConverter(attributes []*AttributeInfo, isResource bool) []*AttributeInfo {
@@ -191,15 +253,13 @@ func filterAttributesForConverter(attributes []*AttributeInfo, isResource bool)
 	return filtered
 }
 
-func newBrokerEntity(inputs EntityInputs, isResource bool) brokerEntity[schema.Schema] {
-	addObjectConverters(inputs.Attributes, isResource)
-	tfAttributes := terraformAttributeMap(inputs.Attributes, isResource, inputs.ObjectType == ReplaceOnlyObject)
+// identifyingAttributesOf returns the identifying attributes of an object type in the order of its path template,
+// which is the order of the import identifier
+func identifyingAttributesOf(inputs EntityInputs) []*AttributeInfo {
 	var identifyingAttributes []*AttributeInfo
-	identifyingAttributesMap := map[string]string{}
 	for _, attr := range inputs.Attributes {
 		if attr.Identifying {
 			identifyingAttributes = append(identifyingAttributes, attr)
-			identifyingAttributesMap["{"+attr.SempName+"}"] = "{" + attr.TerraformName + "}"
 		}
 	}
 	sort.Slice(identifyingAttributes, func(i, j int) bool {
@@ -209,6 +269,23 @@ func newBrokerEntity(inputs EntityInputs, isResource bool) brokerEntity[schema.S
 		jIndex := strings.Index(inputs.PathTemplate, "{"+jAttr.SempName+"}")
 		return iIndex < jIndex
 	})
+	return identifyingAttributes
+}
+
+func newBrokerEntity(inputs EntityInputs, isResource bool) brokerEntity[schema.Schema] {
+	if isResource {
+		inputs.Attributes = withWriteOnlyArguments(inputs.Attributes)
+	}
+	addObjectConverters(inputs.Attributes, isResource)
+	tfAttributes := terraformAttributeMap(inputs.Attributes, isResource, inputs.ObjectType == ReplaceOnlyObject)
+	if inMsgVpn(inputs.PathTemplate) {
+		tfAttributes[msgVpnNameAttribute] = optionalMsgVpnName(tfAttributes[msgVpnNameAttribute])
+	}
+	identifyingAttributes := identifyingAttributesOf(inputs)
+	identifyingAttributesMap := map[string]string{}
+	for _, attr := range identifyingAttributes {
+		identifyingAttributesMap["{"+attr.SempName+"}"] = "{" + attr.TerraformName + "}"
+	}
 	unsupportedResourceWarning := ""
 	// Add unsupported warning for any resource not contained within a message vpn
 	if !strings.HasPrefix(inputs.TerraformName, "msg_vpn") {
@@ -252,6 +329,8 @@ func newBrokerEntity(inputs EntityInputs, isResource bool) brokerEntity[schema.S
 			pathTemplate:          inputs.PathTemplate,
 			postPathTemplate:      inputs.PostPathTemplate,
 			terraformName:         inputs.TerraformName,
+			platform:              inputs.Platform,
+			minSempVersion:        inputs.MinSempVersion,
 			objectType:            inputs.ObjectType,
 			identifyingAttributes: identifyingAttributes,
 			attributes:            inputs.Attributes,
@@ -16,8 +16,164 @@
 
 package broker
 
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
 type SempVersionDetail struct {
 	BasePath    string
 	SempVersion string
 	Platform    string
 }
+
+// availableSincePattern finds the SEMP API version in the descriptions of specs generated without MinSempVersion
+var availableSincePattern = regexp.MustCompile(`(?i)\bavailable since SEMP API version (\d+\.\d+)`)
+
+// ParseSempVersion parses a SEMP API version, ignoring the "+" of broker developer versions
+func ParseSempVersion(sempVersion string) (*version.Version, error) {
+	return version.NewVersion(strings.ReplaceAll(sempVersion, "+", ""))
+}
+
+// minSempVersionOf returns the SEMP API version a description says it is available since, or an empty string
+func minSempVersionOf(markdownDescription string) string {
+	match := availableSincePattern.FindStringSubmatch(markdownDescription)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// withMinSempVersions sets the MinSempVersion of an object type and its attributes from their descriptions, if it
+// is not set by the generator
+func withMinSempVersions(inputs EntityInputs) EntityInputs {
+	if inputs.MinSempVersion == "" {
+		inputs.MinSempVersion = minSempVersionOf(inputs.MarkdownDescription)
+	}
+	var fill func(attributes []*AttributeInfo)
+	fill = func(attributes []*AttributeInfo) {
+		for _, attr := range attributes {
+			if attr.MinSempVersion == "" {
+				attr.MinSempVersion = minSempVersionOf(attr.MarkdownDescription)
+			}
+			fill(attr.Attributes)
+		}
+	}
+	fill(inputs.Attributes)
+	return inputs
+}
+
+// requiresNewerSempVersion tells if something available since minSempVersion is not available in a broker SEMP API
+// version, and returns the parsed minimum version
+func requiresNewerSempVersion(minSempVersion string, sempVersion *version.Version) (*version.Version, bool) {
+	if minSempVersion == "" || sempVersion == nil {
+		return nil, false
+	}
+	v, err := version.NewVersion(minSempVersion)
+	if err != nil {
+		return nil, false
+	}
+	return v, sempVersion.LessThan(v)
+}
+
+// UnsupportedResources returns the Terraform names of the resource types of a broker platform that are not available
+// in a broker SEMP API version
+func UnsupportedResources(platform string, sempVersion *version.Version) []string {
+	var names []string
+	for _, e := range PlatformEntities(platform) {
+		if _, newer := requiresNewerSempVersion(e.MinSempVersion, sempVersion); newer {
+			names = append(names, e.TerraformName)
+		}
+	}
+	return names
+}
+
+// checkSempVersion returns an error if the object type is not available in the SEMP API version of the broker, its
+// SEMP API version is known once the broker API has been checked
+func (b *brokerEntityBase) checkSempVersion() error {
+	if v, newer := requiresNewerSempVersion(b.minSempVersion, b.client.sempVersion); newer {
+		return fmt.Errorf("%v_%v requires broker SEMP API version %v, the broker has SEMP API version %v", providerTypeName, b.terraformName, v.Original(), b.client.sempVersion.Original())
+	}
+	return nil
+}
+
+// checkSempVersionPlan checks that the object type and the configured attributes are available in the SEMP API
+// version of the broker. The broker API is checked at plan time for this if anything may not be available.
+func (r *brokerResource) checkSempVersionPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	if request.Plan.Raw.IsNull() || r.client == nil || r.client.skipApiCheck {
+		return
+	}
+	minRequired, _ := version.NewVersion(minRequiredBrokerSempApiVersion)
+	_, entityGated := requiresNewerSempVersion(r.minSempVersion, minRequired)
+	if !entityGated && !configuresGatedAttribute(r.attributes, request.Config.Raw, minRequired) {
+		return
+	}
+	if err := checkBrokerRequirements(ctx, r.client); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Broker check failed", err)
+		return
+	}
+	variant, err := r.onBrokerPlatform()
+	if err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Unsupported resource", err)
+		return
+	}
+	if err := variant.checkSempVersion(); err != nil {
+		addErrorToDiagnostics(&response.Diagnostics, "Unsupported resource", err)
+		return
+	}
+	checkAttributeSempVersions(variant.attributes, request.Config.Raw, path.Empty(), r.client.sempVersion, &response.Diagnostics)
+}
+
+// configuresGatedAttribute tells if a configuration sets an attribute that is not available in all broker SEMP API
+// versions the provider supports
+func configuresGatedAttribute(attributes []*AttributeInfo, config tftypes.Value, minRequired *version.Version) bool {
+	values := map[string]tftypes.Value{}
+	if config.IsNull() || !config.IsKnown() || config.As(&values) != nil {
+		return false
+	}
+	for _, attr := range attributes {
+		v, ok := values[attr.TerraformName]
+		if !ok || v.IsNull() {
+			continue
+		}
+		if _, gated := requiresNewerSempVersion(attr.MinSempVersion, minRequired); gated {
+			return true
+		}
+		if attr.Attributes != nil && configuresGatedAttribute(attr.Attributes, v, minRequired) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAttributeSempVersions adds an error for each configured attribute that is not available in the broker SEMP API
+// version
+func checkAttributeSempVersions(attributes []*AttributeInfo, config tftypes.Value, p path.Path, sempVersion *version.Version, diags *diag.Diagnostics) {
+	values := map[string]tftypes.Value{}
+	if config.IsNull() || !config.IsKnown() || config.As(&values) != nil {
+		return
+	}
+	for _, attr := range attributes {
+		v, ok := values[attr.TerraformName]
+		if !ok || v.IsNull() {
+			continue
+		}
+		attrPath := p.AtName(attr.TerraformName)
+		if minSempVersion, newer := requiresNewerSempVersion(attr.MinSempVersion, sempVersion); newer {
+			diags.AddAttributeError(attrPath, "Unsupported attribute",
+				fmt.Sprintf("%v requires broker SEMP API version %v, the broker has SEMP API version %v", attrPath, minSempVersion.Original(), sempVersion.Original()))
+			continue
+		}
+		if attr.Attributes != nil {
+			checkAttributeSempVersions(attr.Attributes, v, attrPath, sempVersion, diags)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestWithMinSempVersions(t *testing.T) {
+	inputs := withMinSempVersions(EntityInputs{
+		MarkdownDescription: "A queue.\n\nThis has been available since SEMP API version 2.0.",
+		Attributes: []*AttributeInfo{
+			{TerraformName: "a", MarkdownDescription: "Available since SEMP API version 2.36."},
+			{TerraformName: "b", MarkdownDescription: "Deprecated since SEMP API version 2.36."},
+			{TerraformName: "c", MarkdownDescription: "Available since SEMP API version 2.36.", MinSempVersion: "2.40"},
+			{TerraformName: "d", Attributes: []*AttributeInfo{
+				{TerraformName: "e", MarkdownDescription: "Available since SEMP API version 2.41."},
+			}},
+		},
+	})
+	if inputs.MinSempVersion != "2.0" {
+		t.Errorf("unexpected object type MinSempVersion %v", inputs.MinSempVersion)
+	}
+	for attr, want := range map[*AttributeInfo]string{
+		inputs.Attributes[0]:               "2.36",
+		inputs.Attributes[1]:               "",
+		inputs.Attributes[2]:               "2.40",
+		inputs.Attributes[3].Attributes[0]: "2.41",
+	} {
+		if attr.MinSempVersion != want {
+			t.Errorf("unexpected MinSempVersion %v of %v, want %v", attr.MinSempVersion, attr.TerraformName, want)
+		}
+	}
+}
+
+func TestSempVersionPlan(t *testing.T) {
+	ctx := context.Background()
+	// the fake broker has SEMP API version 2.40
+	b := newFakeBroker(t, SempDetail.Platform)
+	tests := []struct {
+		name                   string
+		attrMinSempVersion     string
+		resourceMinSempVersion string
+		maxTtl                 tftypes.Value
+		wantError              string
+	}{
+		{name: "not configured", maxTtl: tftypes.NewValue(tftypes.Number, nil)},
+		{name: "available attribute", maxTtl: tftypes.NewValue(tftypes.Number, 10)},
+		{name: "newer attribute", maxTtl: tftypes.NewValue(tftypes.Number, 10), attrMinSempVersion: "2.45", wantError: "max_ttl requires broker SEMP API version 2.45, the broker has SEMP API version 2.40"},
+		{name: "newer resource", maxTtl: tftypes.NewValue(tftypes.Number, nil), resourceMinSempVersion: "2.45", wantError: "solacebroker_msg_vpn_queue_test requires broker SEMP API version 2.45, the broker has SEMP API version 2.40"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inputs := msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+			attrMinSempVersion := "2.36"
+			if test.attrMinSempVersion != "" {
+				attrMinSempVersion = test.attrMinSempVersion
+			}
+			inputs.MinSempVersion = test.resourceMinSempVersion
+			inputs.Attributes = append(inputs.Attributes, &AttributeInfo{BaseType: Int64, SempName: "maxTtl", TerraformName: "max_ttl", MinSempVersion: attrMinSempVersion,
+				Type: types.Int64Type, TerraformType: tftypes.Number, Converter: IntegerConverter{}})
+			r := brokerResource(newBrokerResource(inputs))
+			r.client = configureTestProvider(t, fakeBrokerProviderConfig(b.URL, false))
+			config := tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+				"msg_vpn_name": tftypes.NewValue(tftypes.String, "default"),
+				"queue_name":   tftypes.NewValue(tftypes.String, "q1"),
+				"max_ttl":      test.maxTtl,
+			})
+			request := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: r.schema, Raw: config},
+				Plan:   tfsdk.Plan{Schema: r.schema, Raw: config},
+				State:  tfsdk.State{Schema: r.schema, Raw: tftypes.NewValue(r.schema.Type().TerraformType(ctx), nil)},
+			}
+			response := &resource.ModifyPlanResponse{Plan: request.Plan}
+			r.ModifyPlan(ctx, request, response)
+			if test.wantError == "" {
+				if response.Diagnostics.HasError() {
+					t.Errorf("unexpected diagnostics %v", response.Diagnostics)
+				}
+				return
+			}
+			if !response.Diagnostics.HasError() || !strings.Contains(response.Diagnostics.Errors()[0].Detail(), test.wantError) {
+				t.Errorf("expected error %q, got %v", test.wantError, response.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Topic syntaxes of the broker
+const (
+	smfSyntax  = "smf"
+	mqttSyntax = "mqtt"
+)
+
+// maxTopicLength is the maximum length of a topic or subscription in bytes, maxTopicLevels the maximum number of levels
+const (
+	maxTopicLength = 250
+	maxTopicLevels = 128
+)
+
+var ErrInvalidTopic = errors.New("invalid topic")
+
+func checkTopicSyntax(syntax string) error {
+	if syntax != smfSyntax && syntax != mqttSyntax {
+		return fmt.Errorf("unknown topic syntax %q, expected %q or %q", syntax, smfSyntax, mqttSyntax)
+	}
+	return nil
+}
+
+// subscriptionLevels returns the levels of an SMF subscription, without the #noexport/ and #share/<group>/ prefixes
+// of shared and non-exported subscriptions
+func subscriptionLevels(subscription string) []string {
+	levels := strings.Split(subscription, "/")
+	if len(levels) > 1 && levels[0] == "#noexport" {
+		levels = levels[1:]
+	}
+	if len(levels) > 2 && levels[0] == "#share" {
+		levels = levels[2:]
+	}
+	return levels
+}
+
+// validateTopic returns an error if a topic or topic subscription is not valid in a topic syntax. In SMF, "*" is a
+// wildcard if it is the last character of a level and ">" if it is the last level. In MQTT, "+" is a wildcard if it is
+// a whole level and "#" if it is the last level, they may not be used otherwise.
+func validateTopic(topic string, syntax string) error {
+	if err := checkTopicSyntax(syntax); err != nil {
+		return err
+	}
+	if topic == "" {
+		return fmt.Errorf("%w: the topic is empty", ErrInvalidTopic)
+	}
+	if len(topic) > maxTopicLength {
+		return fmt.Errorf("%w: the topic is longer than %d bytes", ErrInvalidTopic, maxTopicLength)
+	}
+	if strings.ContainsRune(topic, 0) {
+		return fmt.Errorf("%w: the topic contains a null character", ErrInvalidTopic)
+	}
+	levels := strings.Split(topic, "/")
+	if syntax == smfSyntax {
+		levels = subscriptionLevels(topic)
+	}
+	if len(levels) > maxTopicLevels {
+		return fmt.Errorf("%w: the topic has more than %d levels", ErrInvalidTopic, maxTopicLevels)
+	}
+	for i, level := range levels {
+		switch syntax {
+		case smfSyntax:
+			if level == "" {
+				return fmt.Errorf("%w: level %d is empty", ErrInvalidTopic, i+1)
+			}
+		case mqttSyntax:
+			if strings.Contains(level, "+") && level != "+" {
+				return fmt.Errorf("%w: the + wildcard must be a whole level, at level %d", ErrInvalidTopic, i+1)
+			}
+			if strings.Contains(level, "#") && (level != "#" || i != len(levels)-1) {
+				return fmt.Errorf("%w: the # wildcard must be the whole last level, at level %d", ErrInvalidTopic, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// topicMatches tells if a topic subscription matches a topic in a topic syntax
+func topicMatches(subscription string, topic string, syntax string) (bool, error) {
+	if err := checkTopicSyntax(syntax); err != nil {
+		return false, err
+	}
+	if syntax == mqttSyntax {
+		return mqttTopicMatches(strings.Split(subscription, "/"), strings.Split(topic, "/")), nil
+	}
+	return smfTopicMatches(subscriptionLevels(subscription), strings.Split(topic, "/")), nil
+}
+
+func smfTopicMatches(subscription []string, topic []string) bool {
+	for i, level := range subscription {
+		if level == ">" && i == len(subscription)-1 {
+			// matches one or more levels
+			return len(topic) > i
+		}
+		if i >= len(topic) {
+			return false
+		}
+		switch {
+		case level == "*":
+		case strings.HasSuffix(level, "*"):
+			if !strings.HasPrefix(topic[i], strings.TrimSuffix(level, "*")) {
+				return false
+			}
+		case level != topic[i]:
+			return false
+		}
+	}
+	return len(topic) == len(subscription)
+}
+
+func mqttTopicMatches(subscription []string, topic []string) bool {
+	if (subscription[0] == "+" || subscription[0] == "#") && strings.HasPrefix(topic[0], "$") {
+		// wildcards do not match topics of the server starting with $
+		return false
+	}
+	for i, level := range subscription {
+		if level == "#" && i == len(subscription)-1 {
+			// matches the parent level and any number of levels
+			return len(topic) >= i
+		}
+		if i >= len(topic) {
+			return false
+		}
+		if level != "+" && level != topic[i] {
+			return false
+		}
+	}
+	return len(topic) == len(subscription)
+}
@@ -17,19 +17,39 @@
 package broker
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"terraform-provider-solacebroker/internal/semp"
 	"time"
+	"unicode"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
+// copyObjectValues returns the attribute values of an object value. The map that As returns is shared with the
+// value, so it is copied to allow changing it without changing the value.
+func copyObjectValues(v tftypes.Value) (map[string]tftypes.Value, error) {
+	values := map[string]tftypes.Value{}
+	if err := v.As(&values); err != nil {
+		return nil, err
+	}
+	m := make(map[string]tftypes.Value, len(values))
+	for name, value := range values {
+		m[name] = value
+	}
+	return m, nil
+}
+
 func resolveSempPath(pathTemplate string, attributes []*AttributeInfo, v tftypes.Value) (string, error) {
 	m := map[string]tftypes.Value{}
 	err := v.As(&m)
@@ -44,6 +64,11 @@ func resolveSempPath(pathTemplate string, attributes []*AttributeInfo, v tftypes
 		}
 		identifiers[attr.SempName] = fmt.Sprintf("%v", v)
 	}
+	return resolvePathTemplate(pathTemplate, identifiers)
+}
+
+// resolvePathTemplate replaces the parameters of a SEMP path template with the URL-encoded identifiers by SEMP name
+func resolvePathTemplate(pathTemplate string, identifiers map[string]string) (string, error) {
 	// doing it this way identifies missed parameters (as opposed to doing strings.Replace or something like that)
 	var path string
 	split := strings.SplitN(pathTemplate, "{", 2)
@@ -76,6 +101,84 @@ func stringWithDefaultFromEnv(value types.String, name string) (string, error) {
 	return s, nil
 }
 
+// stringListWithDefaultFromEnv falls back to a comma or space separated list from the environment
+func stringListWithDefaultFromEnv(value types.List, name string) ([]string, error) {
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("cannot use unknown value as %v", name)
+	}
+
+	if !value.IsNull() {
+		var list []string
+		for _, element := range value.Elements() {
+			s, ok := element.(types.String)
+			if !ok || s.IsUnknown() {
+				return nil, fmt.Errorf("cannot use unknown value as %v", name)
+			}
+			list = append(list, s.ValueString())
+		}
+		return list, nil
+	}
+
+	s := os.Getenv("SOLACEBROKER_" + strings.ToUpper(name))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}), nil
+}
+
+// patternListWithDefaultFromEnv compiles a list of regular expressions, falling back to a newline-separated list from
+// the environment as regular expressions may contain commas and spaces
+func patternListWithDefaultFromEnv(value types.List, name string) ([]*regexp.Regexp, error) {
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("cannot use unknown value as %v", name)
+	}
+
+	var patterns []string
+	if !value.IsNull() {
+		for _, element := range value.Elements() {
+			s, ok := element.(types.String)
+			if !ok || s.IsUnknown() {
+				return nil, fmt.Errorf("cannot use unknown value in %v", name)
+			}
+			patterns = append(patterns, s.ValueString())
+		}
+	} else {
+		for _, line := range strings.Split(os.Getenv("SOLACEBROKER_"+strings.ToUpper(name)), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				patterns = append(patterns, line)
+			}
+		}
+	}
+	compiled, err := semp.ParseRedactPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("%v is not valid; %w", name, err)
+	}
+	return compiled, nil
+}
+
+// stringMapWithDefaultFromEnv falls back to a comma-separated list of name=value pairs from the environment
+func stringMapWithDefaultFromEnv(value types.Map, name string) (map[string]string, error) {
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("cannot use unknown value as %v", name)
+	}
+
+	if !value.IsNull() {
+		m := map[string]string{}
+		for k, element := range value.Elements() {
+			s, ok := element.(types.String)
+			if !ok || s.IsUnknown() {
+				return nil, fmt.Errorf("cannot use unknown value in %v", name)
+			}
+			m[k] = s.ValueString()
+		}
+		return m, nil
+	}
+	m, err := semp.ParseRequestHeaders(os.Getenv("SOLACEBROKER_" + strings.ToUpper(name)))
+	if err != nil {
+		return nil, fmt.Errorf("%v is not valid; %w", name, err)
+	}
+	return m, nil
+}
+
 func int64WithDefaultFromEnv(value types.Int64, name string, def int64) (int64, error) {
 	if value.IsUnknown() {
 		return 0, fmt.Errorf("cannot use unknown value as %v", name)
@@ -134,51 +237,224 @@ func durationWithDefaultFromEnv(value types.String, name string, def time.Durati
 	return d, nil
 }
 
-func client(providerData *providerData) (*semp.Client, diag.Diagnostic) {
+// secretWithDefaultFromEnv falls back to the environment, and then to the file named by the environment variable with
+// the _FILE suffix
+func secretWithDefaultFromEnv(value types.String, name string) (string, error) {
+	s, err := stringWithDefaultFromEnv(value, name)
+	if err != nil || s != "" || !value.IsNull() {
+		return s, err
+	}
+	if path := os.Getenv("SOLACEBROKER_" + strings.ToUpper(name) + "_FILE"); path != "" {
+		return semp.ReadSecretFile(path)
+	}
+	return "", nil
+}
+
+// resolveCredentialSources sets the credentials provided by password_file, bearer_token_file and credential_process in
+// the provider block, so that they are treated like credentials set in the provider block
+func resolveCredentialSources(providerData *providerData) diag.Diagnostic {
+	for _, source := range []struct {
+		file  types.String
+		value *types.String
+		name  string
+	}{
+		{providerData.PasswordFile, &providerData.Password, "password"},
+		{providerData.BearerTokenFile, &providerData.BearerToken, "bearer_token"},
+	} {
+		if source.file.IsUnknown() {
+			return diag.NewErrorDiagnostic("Unable to parse provider attribute", fmt.Sprintf("cannot use unknown value as %v_file", source.name))
+		}
+		if source.file.IsNull() {
+			continue
+		}
+		if !source.value.IsNull() {
+			return diag.NewErrorDiagnostic(fmt.Sprintf("Cannot use both %v and %v_file", source.name, source.name), semp.ErrProviderParametersError.Error())
+		}
+		secret, err := semp.ReadSecretFile(source.file.ValueString())
+		if err != nil {
+			return diag.NewErrorDiagnostic("Unable to read provider attribute "+source.name+"_file", err.Error())
+		}
+		*source.value = types.StringValue(secret)
+	}
+	credentialProcess, err := stringWithDefaultFromEnv(providerData.CredentialProcess, "credential_process")
+	if err != nil {
+		return diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if credentialProcess == "" {
+		return nil
+	}
+	if !providerData.Username.IsNull() || !providerData.Password.IsNull() || !providerData.BearerToken.IsNull() {
+		return diag.NewErrorDiagnostic("Cannot use credential_process with username, password or bearer_token", semp.ErrProviderParametersError.Error())
+	}
+	credentials, err := semp.RunCredentialProcess(context.Background(), credentialProcess)
+	if err != nil {
+		return diag.NewErrorDiagnostic("Unable to get credentials from credential_process", err.Error())
+	}
+	if credentials.BearerToken != "" {
+		providerData.BearerToken = types.StringValue(credentials.BearerToken)
+	} else {
+		providerData.Username = types.StringValue(credentials.Username)
+		providerData.Password = types.StringValue(credentials.Password)
+	}
+	return nil
+}
+
+func client(providerData *providerData) (*brokerClient, diag.Diagnostic) {
+	if d := resolveCredentialSources(providerData); d != nil {
+		return nil, d
+	}
 	// Check for params credentials conflicts
 	// Logic:
 	// If there is any 1 complete set of credentials in the provider block those are always used and are the priority.
 	// If there is not any 1 complete set of credentials in the provider block then look for 1 complete set in the env vars.
 	// If there are multiple complete sets in either the provider block or env vars this is an error.
 	// If there are no complete sets in the env vars this is an error.
-	var username, password, bearerToken string
-	if !providerData.BearerToken.IsNull() && providerData.Username.IsNull() && providerData.Password.IsNull() ||
-		providerData.BearerToken.IsNull() && !providerData.Username.IsNull() && !providerData.Password.IsNull() {
+	var username, password, bearerToken, clientCertificate, clientPrivateKey, oauthTokenURL, oauthClientID, oauthClientSecret string
+	basicAuthInParams := !providerData.Username.IsNull() || !providerData.Password.IsNull()
+	bearerTokenInParams := !providerData.BearerToken.IsNull()
+	clientCertificateInParams := !providerData.ClientCertificate.IsNull() || !providerData.ClientPrivateKey.IsNull()
+	oauthInParams := !providerData.OAuthTokenURL.IsNull() || !providerData.OAuthClientID.IsNull() || !providerData.OAuthClientSecret.IsNull()
+	credentialSetsInParams := [][]types.String{
+		{providerData.Username, providerData.Password},
+		{providerData.BearerToken},
+		{providerData.ClientCertificate, providerData.ClientPrivateKey},
+		{providerData.OAuthTokenURL, providerData.OAuthClientID, providerData.OAuthClientSecret},
+	}
+	setsInParams, completeSetsInParams := 0, 0
+	for _, credentialSet := range credentialSetsInParams {
+		set, complete := false, true
+		for _, value := range credentialSet {
+			set = set || !value.IsNull()
+			complete = complete && !value.IsNull()
+		}
+		if set {
+			setsInParams++
+			if complete {
+				completeSetsInParams++
+			}
+		}
+	}
+	if setsInParams == 1 && completeSetsInParams == 1 {
 		// these are valid combinations in the provider block, no need to check further
 		username = providerData.Username.ValueString()
 		password = providerData.Password.ValueString()
 		bearerToken = providerData.BearerToken.ValueString()
+		clientCertificate = providerData.ClientCertificate.ValueString()
+		clientPrivateKey = providerData.ClientPrivateKey.ValueString()
+		oauthTokenURL = providerData.OAuthTokenURL.ValueString()
+		oauthClientID = providerData.OAuthClientID.ValueString()
+		oauthClientSecret = providerData.OAuthClientSecret.ValueString()
 	} else {
 		var err error
-		// username, password and bearer token will be set to "" if not provided through env or config
+		// credentials will be set to "" if not provided through env or config
 		username, err = stringWithDefaultFromEnv(providerData.Username, "username")
 		if err != nil {
 			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 		}
-		password, err = stringWithDefaultFromEnv(providerData.Password, "password")
+		password, err = secretWithDefaultFromEnv(providerData.Password, "password")
 		if err != nil {
 			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 		}
-		bearerToken, err = stringWithDefaultFromEnv(providerData.BearerToken, "bearer_token")
+		bearerToken, err = secretWithDefaultFromEnv(providerData.BearerToken, "bearer_token")
 		if err != nil {
 			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 		}
-		if username == "" && password == "" && bearerToken == "" {
-			return nil, diag.NewErrorDiagnostic("Bearer token or basic authentication credentials must be provided", semp.ErrProviderParametersError.Error())
+		clientCertificate, err = stringWithDefaultFromEnv(providerData.ClientCertificate, "client_certificate")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 		}
-		if (!providerData.BearerToken.IsNull() && (!providerData.Username.IsNull() || !providerData.Password.IsNull())) ||
+		clientPrivateKey, err = stringWithDefaultFromEnv(providerData.ClientPrivateKey, "client_private_key")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+		oauthTokenURL, err = stringWithDefaultFromEnv(providerData.OAuthTokenURL, "oauth_token_url")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+		oauthClientID, err = stringWithDefaultFromEnv(providerData.OAuthClientID, "oauth_client_id")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+		oauthClientSecret, err = stringWithDefaultFromEnv(providerData.OAuthClientSecret, "oauth_client_secret")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+		oauthSet := oauthTokenURL != "" || oauthClientID != "" || oauthClientSecret != ""
+		if username == "" && password == "" && bearerToken == "" && clientCertificate == "" && clientPrivateKey == "" && !oauthSet {
+			return nil, diag.NewErrorDiagnostic("Bearer token, basic authentication, client certificate or OAuth client credentials must be provided", semp.ErrProviderParametersError.Error())
+		}
+		if (bearerTokenInParams && basicAuthInParams) ||
 			(bearerToken != "" && (username != "" || password != "")) {
 			return nil, diag.NewErrorDiagnostic("Cannot use Bearer token with basic authentication credentials", semp.ErrProviderParametersError.Error())
 		}
+		if (clientCertificateInParams && (bearerTokenInParams || basicAuthInParams)) ||
+			((clientCertificate != "" || clientPrivateKey != "") && (bearerToken != "" || username != "" || password != "")) {
+			return nil, diag.NewErrorDiagnostic("Cannot use client certificate with Bearer token or basic authentication credentials", semp.ErrProviderParametersError.Error())
+		}
+		if (oauthInParams && (bearerTokenInParams || basicAuthInParams || clientCertificateInParams)) ||
+			(oauthSet && (bearerToken != "" || username != "" || password != "" || clientCertificate != "" || clientPrivateKey != "")) {
+			return nil, diag.NewErrorDiagnostic("Cannot use OAuth client credentials with Bearer token, basic authentication or client certificate credentials", semp.ErrProviderParametersError.Error())
+		}
 		if !providerData.Username.IsNull() && providerData.Password.IsNull() || providerData.Username.IsNull() && !providerData.Password.IsNull() ||
 			username != "" && password == "" || username == "" && password != "" {
 			return nil, diag.NewErrorDiagnostic("Both username and password must be provided for basic authentication and cannot mix params and env vars", semp.ErrProviderParametersError.Error())
 		}
+		if !providerData.ClientCertificate.IsNull() && providerData.ClientPrivateKey.IsNull() || providerData.ClientCertificate.IsNull() && !providerData.ClientPrivateKey.IsNull() ||
+			clientCertificate != "" && clientPrivateKey == "" || clientCertificate == "" && clientPrivateKey != "" {
+			return nil, diag.NewErrorDiagnostic("Both client certificate and private key must be provided for client certificate authentication and cannot mix params and env vars", semp.ErrProviderParametersError.Error())
+		}
+		if oauthSet && (oauthTokenURL == "" || oauthClientID == "" || oauthClientSecret == "") ||
+			oauthInParams && (providerData.OAuthTokenURL.IsNull() || providerData.OAuthClientID.IsNull() || providerData.OAuthClientSecret.IsNull()) {
+			return nil, diag.NewErrorDiagnostic("OAuth token URL, client ID and client secret must all be provided for OAuth authentication and cannot mix params and env vars", semp.ErrProviderParametersError.Error())
+		}
+	}
+	var options []semp.Option
+	if clientCertificate != "" {
+		clientPrivateKeyPassword, err := stringWithDefaultFromEnv(providerData.ClientPrivateKeyPassword, "client_private_key_password")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+		certificate, err := semp.LoadClientCertificate(clientCertificate, clientPrivateKey, clientPrivateKeyPassword)
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to load client certificate", err.Error())
+		}
+		options = append(options, semp.ClientCertificate(certificate))
+	}
+	if oauthTokenURL != "" {
+		oauthScopes, err := stringListWithDefaultFromEnv(providerData.OAuthScopes, "oauth_scopes")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+		options = append(options, semp.OAuthClientCredentials(oauthTokenURL, oauthClientID, oauthClientSecret, oauthScopes))
+	}
+	sessionLogin, err := booleanWithDefaultFromEnv(providerData.SessionLogin, "session_login", false)
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if sessionLogin {
+		if username == "" {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", "session_login requires username and password")
+		}
+		options = append(options, semp.SessionLogin())
 	}
-	url, err := stringWithDefaultFromEnv(providerData.Url, "url")
+	nodeURLs, err := stringListWithDefaultFromEnv(providerData.Urls, "urls")
 	if err != nil {
 		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 	}
+	var url string
+	if len(nodeURLs) == 0 || !providerData.Url.IsNull() {
+		// the url environment variable does not apply if urls is set
+		url, err = stringWithDefaultFromEnv(providerData.Url, "url")
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+	}
+	if url == "" && len(nodeURLs) == 0 || url != "" && len(nodeURLs) != 0 {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", "either url or urls must be set")
+	}
+	if url != "" {
+		nodeURLs = []string{url}
+	}
 	retries, err := int64WithDefaultFromEnv(providerData.Retries, "retries", semp.DefaultRetries)
 	if err != nil {
 		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
@@ -199,24 +475,158 @@ func client(providerData *providerData) (*semp.Client, diag.Diagnostic) {
 	if err != nil {
 		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 	}
+	requestBurstSize, err := int64WithDefaultFromEnv(providerData.RequestBurstSize, "request_burst_size", semp.DefaultRequestBurst)
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if requestBurstSize < 1 {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", "request_burst_size must be at least 1")
+	}
+	maxConcurrentRequests, err := int64WithDefaultFromEnv(providerData.MaxConcurrentRequests, "max_concurrent_requests", semp.DefaultMaxConcurrent)
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if maxConcurrentRequests < 1 {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", "max_concurrent_requests must be at least 1")
+	}
 	insecureSkipVerify, err := booleanWithDefaultFromEnv(providerData.InsecureSkipVerify, "insecure_skip_verify", false)
 	if err != nil {
 		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 	}
-	url = getFullSempAPIURL(url)
-	skipApiCheck, err = booleanWithDefaultFromEnv(providerData.SkipApiCheck, "skip_api_check", false) // This variable is used in resource
+	caCertificate, err := stringWithDefaultFromEnv(providerData.CACertificate, "ca_certificate")
 	if err != nil {
 		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
 	}
-	client := semp.NewClient(
-		url,
-		insecureSkipVerify,
-		true, // this is a client for the provider
+	var rootCAs *x509.CertPool
+	if caCertificate != "" {
+		rootCAs, err = semp.LoadCACertificates(caCertificate)
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to load CA certificate", err.Error())
+		}
+	}
+	tlsServerName, err := stringWithDefaultFromEnv(providerData.TLSServerName, "tls_server_name")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	tlsMinVersionString, err := stringWithDefaultFromEnv(providerData.TLSMinVersion, "tls_min_version")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	tlsMinVersion := uint16(tls.VersionTLS12)
+	if tlsMinVersionString != "" {
+		tlsMinVersion, err = semp.ParseTLSVersion(tlsMinVersionString)
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+	}
+	proxyURL, err := stringWithDefaultFromEnv(providerData.ProxyURL, "proxy_url")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if proxyURL != "" {
+		if err := semp.ParseProxyURL(proxyURL); err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+	}
+	noProxy, err := stringWithDefaultFromEnv(providerData.NoProxy, "no_proxy")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if proxyURL != "" || noProxy != "" {
+		options = append(options, semp.Proxy(proxyURL, noProxy))
+	}
+	requestHeaders, err := stringMapWithDefaultFromEnv(providerData.RequestHeaders, "request_headers")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	hostHeader, err := stringWithDefaultFromEnv(providerData.HostHeader, "host_header")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if len(requestHeaders) > 0 || hostHeader != "" {
+		options = append(options, semp.RequestHeaders(requestHeaders, hostHeader))
+	}
+	redactPatterns, err := patternListWithDefaultFromEnv(providerData.RedactPatterns, "redact_patterns")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	defaultMsgVpnName, err := stringWithDefaultFromEnv(providerData.DefaultMsgVpnName, "default_msg_vpn_name")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	readOnly, err := booleanWithDefaultFromEnv(providerData.ReadOnly, "read_only", false)
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	allowedMsgVpns, err := stringListWithDefaultFromEnv(providerData.AllowedMsgVpns, "allowed_msg_vpns")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	deniedMsgVpns, err := stringListWithDefaultFromEnv(providerData.DeniedMsgVpns, "denied_msg_vpns")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	guard, err := newMsgVpnGuard(allowedMsgVpns, deniedMsgVpns)
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	brokerVersionConstraint, err := stringWithDefaultFromEnv(providerData.BrokerVersionConstraint, "broker_version_constraint")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	var constraints version.Constraints
+	if brokerVersionConstraint != "" {
+		constraints, err = version.NewConstraint(brokerVersionConstraint)
+		if err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", fmt.Sprintf("invalid broker_version_constraint: %v", err))
+		}
+	}
+	var urls []string
+	for _, nodeURL := range nodeURLs {
+		urls = append(urls, getFullSempAPIURL(nodeURL))
+	}
+	skipApiCheck, err := booleanWithDefaultFromEnv(providerData.SkipApiCheck, "skip_api_check", false)
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	opaquePassword, err := stringWithDefaultFromEnv(providerData.OpaquePassword, "opaque_password")
+	if err != nil {
+		return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+	}
+	if opaquePassword != "" {
+		if err := checkOpaquePassword(opaquePassword, urls); err != nil {
+			return nil, diag.NewErrorDiagnostic("Unable to parse provider attribute", err.Error())
+		}
+	}
+	options = append(options,
 		semp.BasicAuth(username, password),
 		semp.BearerToken(bearerToken),
+		semp.TLSSettings(rootCAs, tlsServerName, tlsMinVersion),
 		semp.Retries(retries, retryMinInterval, retryMaxInterval),
-		semp.RequestLimits(requestTimeoutDuration, requestMinInterval))
-	return client, nil
+		semp.RequestLimits(requestTimeoutDuration, requestMinInterval),
+		semp.RequestBurst(requestBurstSize),
+		semp.MaxConcurrentRequests(maxConcurrentRequests),
+		semp.FailoverURLs(urls[1:]...),
+		semp.PathTemplates(PathTemplates()...),
+		semp.SensitiveAttributes(SensitiveAttributeNames()...),
+		semp.RedactPatterns(redactPatterns...),
+		semp.ReadOnly(readOnly))
+	client := semp.NewClient(
+		urls[0],
+		insecureSkipVerify,
+		true, // this is a client for the provider
+		options...)
+	// the opaque password is sent as a query parameter of refreshes, mask it in request URLs
+	client.AddSecrets(opaquePassword)
+	return &brokerClient{
+		Client:                  client,
+		skipApiCheck:            skipApiCheck,
+		opaquePassword:          opaquePassword,
+		defaultMsgVpnName:       defaultMsgVpnName,
+		msgVpnGuard:             guard,
+		brokerVersionConstraint: constraints,
+		statsBroker:             strings.Join(nodeURLs, ","),
+	}, nil
 }
 
 func getFullSempAPIURL(url string) string {
@@ -2,8 +2,10 @@ package broker
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -23,7 +25,7 @@ func TestClient(t *testing.T) {
 		{"", "", "", "testuser", "testpassword", "testbearertoken", "Cannot use Bearer token with basic authentication credentials"},
 		{"", "", "", "testuser", "", "testbearertoken", "Cannot use Bearer token with basic authentication credentials"},
 		{"", "", "", "", "testpassword", "testbearertoken", "Cannot use Bearer token with basic authentication credentials"},
-		{"", "", "", "", "", "", "Bearer token or basic authentication credentials must be provided"},
+		{"", "", "", "", "", "", "Bearer token, basic authentication, client certificate or OAuth client credentials must be provided"},
 		{"testuser", "testpassword", "", "", "", "", ""},
 		{"", "", "testbearertoken", "", "", "", ""},
 		{"", "", "testbearertoken", "", "", "testbearertoken", ""},
@@ -83,3 +85,202 @@ func TestClient(t *testing.T) {
 		}
 	}
 }
+
+func TestClientCertificateCredentials(t *testing.T) {
+	const certificate = "../semp/testdata/client.crt"
+	const privateKey = "../semp/testdata/client.key"
+	const encryptedPrivateKey = "../semp/testdata/client-encrypted.key"
+	matrix := []struct {
+		ParamCertificate string
+		ParamPrivateKey  string
+		ParamKeyPassword string
+		ParamBearertoken string
+		EnvCertificate   string
+		EnvPrivateKey    string
+		EnvUsername      string
+		Expected         string
+	}{
+		{certificate, privateKey, "", "", "", "", "", ""},
+		{certificate, encryptedPrivateKey, "testpass", "", "", "", "", ""},
+		{"", "", "", "", certificate, privateKey, "", ""},
+		{certificate, privateKey, "", "", "", "", "testuser", ""},
+		{certificate, encryptedPrivateKey, "", "", "", "", "", "Unable to load client certificate"},
+		{certificate, encryptedPrivateKey, "wrongpass", "", "", "", "", "Unable to load client certificate"},
+		{certificate, "", "", "", "", "", "", "Both client certificate and private key must be provided for client certificate authentication and cannot mix params and env vars"},
+		{certificate, "", "", "", "", privateKey, "", "Both client certificate and private key must be provided for client certificate authentication and cannot mix params and env vars"},
+		{certificate, privateKey, "", "testbearertoken", "", "", "", "Cannot use client certificate with Bearer token or basic authentication credentials"},
+		{"", "", "", "", certificate, privateKey, "testuser", "Cannot use client certificate with Bearer token or basic authentication credentials"},
+	}
+
+	for testNr, test := range matrix {
+		t.Setenv("SOLACEBROKER_CLIENT_CERTIFICATE", test.EnvCertificate)
+		t.Setenv("SOLACEBROKER_CLIENT_PRIVATE_KEY", test.EnvPrivateKey)
+		t.Setenv("SOLACEBROKER_USERNAME", test.EnvUsername)
+		t.Setenv("SOLACEBROKER_PASSWORD", "")
+		t.Setenv("SOLACEBROKER_BEARER_TOKEN", "")
+
+		stringOrNull := func(s string) types.String {
+			if s == "" {
+				return types.StringNull()
+			}
+			return types.StringValue(s)
+		}
+		providerData := &providerData{
+			Username:                 types.StringNull(),
+			Password:                 types.StringNull(),
+			BearerToken:              stringOrNull(test.ParamBearertoken),
+			ClientCertificate:        stringOrNull(test.ParamCertificate),
+			ClientPrivateKey:         stringOrNull(test.ParamPrivateKey),
+			ClientPrivateKeyPassword: stringOrNull(test.ParamKeyPassword),
+			Url:                      types.StringValue("https://example.com"),
+		}
+		_, diag := client(providerData)
+		if diag != nil {
+			if test.Expected != diag.Summary() {
+				t.Errorf("Test %d: expected %v but got %v: %v", testNr, test.Expected, diag.Summary(), diag.Detail())
+			}
+		} else if test.Expected != "" {
+			t.Errorf("Test %d: expected %v but got nil diag", testNr, test.Expected)
+		}
+	}
+}
+
+func TestOAuthCredentials(t *testing.T) {
+	matrix := []struct {
+		ParamTokenURL     string
+		ParamClientID     string
+		ParamClientSecret string
+		EnvClientSecret   string
+		EnvBearertoken    string
+		Expected          string
+	}{
+		{"https://idp.example.com/token", "client", "secret", "", "", ""},
+		{"https://idp.example.com/token", "client", "secret", "", "testbearertoken", ""},
+		{"https://idp.example.com/token", "client", "", "secret", "", "OAuth token URL, client ID and client secret must all be provided for OAuth authentication and cannot mix params and env vars"},
+		{"https://idp.example.com/token", "client", "", "", "", "OAuth token URL, client ID and client secret must all be provided for OAuth authentication and cannot mix params and env vars"},
+		{"https://idp.example.com/token", "client", "", "secret", "testbearertoken", "Cannot use OAuth client credentials with Bearer token, basic authentication or client certificate credentials"},
+	}
+
+	for testNr, test := range matrix {
+		t.Setenv("SOLACEBROKER_OAUTH_CLIENT_SECRET", test.EnvClientSecret)
+		t.Setenv("SOLACEBROKER_BEARER_TOKEN", test.EnvBearertoken)
+		t.Setenv("SOLACEBROKER_USERNAME", "")
+		t.Setenv("SOLACEBROKER_PASSWORD", "")
+
+		stringOrNull := func(s string) types.String {
+			if s == "" {
+				return types.StringNull()
+			}
+			return types.StringValue(s)
+		}
+		providerData := &providerData{
+			OAuthTokenURL:     stringOrNull(test.ParamTokenURL),
+			OAuthClientID:     stringOrNull(test.ParamClientID),
+			OAuthClientSecret: stringOrNull(test.ParamClientSecret),
+			OAuthScopes:       types.ListNull(types.StringType),
+			Url:               types.StringValue("https://example.com"),
+		}
+		_, diag := client(providerData)
+		if diag != nil {
+			if test.Expected != diag.Summary() {
+				t.Errorf("Test %d: expected %v but got %v: %v", testNr, test.Expected, diag.Summary(), diag.Detail())
+			}
+		} else if test.Expected != "" {
+			t.Errorf("Test %d: expected %v but got nil diag", testNr, test.Expected)
+		}
+	}
+}
+
+func TestCredentialSources(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := dir + "/password"
+	if err := os.WriteFile(passwordFile, []byte("testpassword\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const credentialProcess = `echo '{"username":"testuser","password":"testpassword"}'`
+	matrix := []struct {
+		ParamUsername          string
+		ParamPassword          string
+		ParamPasswordFile      string
+		ParamCredentialProcess string
+		EnvUsername            string
+		EnvPasswordFile        string
+		Expected               string
+	}{
+		{"testuser", "", passwordFile, "", "", "", ""},
+		{"", "", "", "", "testuser", passwordFile, ""},
+		{"", "", "", credentialProcess, "", "", ""},
+		{"", "", "", "", "", "", "Bearer token, basic authentication, client certificate or OAuth client credentials must be provided"},
+		{"testuser", "testpassword", passwordFile, "", "", "", "Cannot use both password and password_file"},
+		{"testuser", "", dir + "/missing", "", "", "", "Unable to read provider attribute password_file"},
+		{"testuser", "", "", credentialProcess, "", "", "Cannot use credential_process with username, password or bearer_token"},
+		{"", "", "", "exit 1", "", "", "Unable to get credentials from credential_process"},
+	}
+
+	for testNr, test := range matrix {
+		t.Setenv("SOLACEBROKER_USERNAME", test.EnvUsername)
+		t.Setenv("SOLACEBROKER_PASSWORD", "")
+		t.Setenv("SOLACEBROKER_PASSWORD_FILE", test.EnvPasswordFile)
+		t.Setenv("SOLACEBROKER_BEARER_TOKEN", "")
+		t.Setenv("SOLACEBROKER_CREDENTIAL_PROCESS", "")
+
+		stringOrNull := func(s string) types.String {
+			if s == "" {
+				return types.StringNull()
+			}
+			return types.StringValue(s)
+		}
+		providerData := &providerData{
+			Username:          stringOrNull(test.ParamUsername),
+			Password:          stringOrNull(test.ParamPassword),
+			PasswordFile:      stringOrNull(test.ParamPasswordFile),
+			CredentialProcess: stringOrNull(test.ParamCredentialProcess),
+			Url:               types.StringValue("https://example.com"),
+		}
+		_, diag := client(providerData)
+		if diag != nil {
+			if test.Expected != diag.Summary() {
+				t.Errorf("Test %d: expected %v but got %v: %v", testNr, test.Expected, diag.Summary(), diag.Detail())
+			}
+		} else if test.Expected != "" {
+			t.Errorf("Test %d: expected %v but got nil diag", testNr, test.Expected)
+		}
+	}
+}
+
+func TestInvalidBrokerVersionConstraint(t *testing.T) {
+	_, d := client(&providerData{
+		Url:                     types.StringValue("https://example.com"),
+		Username:                types.StringValue("admin"),
+		Password:                types.StringValue("admin"),
+		BrokerVersionConstraint: types.StringValue(">= ten"),
+	})
+	if d == nil || !strings.Contains(d.Detail(), "invalid broker_version_constraint") {
+		t.Errorf("expected an invalid constraint to be rejected, got %v", d)
+	}
+}
+
+func TestClientURLs(t *testing.T) {
+	t.Setenv("SOLACEBROKER_URL", "")
+	t.Setenv("SOLACEBROKER_URLS", "")
+	urls := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("https://primary.example.com"), types.StringValue("https://backup.example.com")})
+	tests := []struct {
+		name    string
+		url     types.String
+		urls    types.List
+		wantErr bool
+	}{
+		{name: "url", url: types.StringValue("https://example.com"), urls: types.ListNull(types.StringType)},
+		{name: "urls", url: types.StringNull(), urls: urls},
+		{name: "none", url: types.StringNull(), urls: types.ListNull(types.StringType), wantErr: true},
+		{name: "both", url: types.StringValue("https://example.com"), urls: urls, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, d := client(&providerData{Username: types.StringValue("admin"), Password: types.StringValue("admin"), Url: test.url, Urls: test.urls})
+			if (d != nil) != test.wantErr {
+				t.Errorf("got %v, want error %v", d, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const (
+	writeOnlySuffix        = "_wo"
+	writeOnlyVersionSuffix = "_wo_version"
+)
+
+// secretNameWords are the words of the attribute names of passwords, secrets and keys
+var secretNameWords = []string{"password", "secret", "key", "keytab"}
+
+// hasWriteOnlyArgument reports whether a resource attribute gets a write-only argument. Only the sensitive attributes
+// of secrets do, not those that are sensitive for other reasons, such as the queue behavior of replication. The
+// certificate contents include the private key.
+func hasWriteOnlyArgument(attr *AttributeInfo) bool {
+	if !attr.Sensitive || attr.BaseType != String || attr.Identifying || attr.ReadOnly || attr.WriteOnly {
+		return false
+	}
+	return strings.HasSuffix(attr.TerraformName, "cert_content") ||
+		slices.ContainsFunc(strings.Split(attr.TerraformName, "_"), func(word string) bool {
+			return slices.Contains(secretNameWords, word)
+		})
+}
+
+// withWriteOnlyArguments adds a write-only argument for each secret attribute of a resource, with the suffix _wo,
+// and an attribute with the suffix _wo_version to send it again. Write-only arguments are sent to the broker but never
+// stored in the state, which requires Terraform 1.11 or later. A required attribute becomes optional, exactly one of
+// it and its write-only argument must be set then. Changing the version replaces the resource if changing the
+// attribute does.
+func withWriteOnlyArguments(attributes []*AttributeInfo) []*AttributeInfo {
+	result := append([]*AttributeInfo{}, attributes...)
+	for i, attr := range attributes {
+		if !hasWriteOnlyArgument(attr) {
+			continue
+		}
+		writeOnly := *attr
+		writeOnly.TerraformName = attr.TerraformName + writeOnlySuffix
+		writeOnly.MarkdownDescription = fmt.Sprintf("Write-only variant of `%v`, which is sent to the broker but not stored in the state. It is only sent again if `%v` changes. Requires Terraform 1.11 or later.", attr.TerraformName, attr.TerraformName+writeOnlyVersionSuffix)
+		writeOnly.Description = writeOnly.MarkdownDescription
+		writeOnly.Required = false
+		writeOnly.RequiresReplace = false
+		writeOnly.Default = nil
+		writeOnly.WriteOnly = true
+		if attr.Required {
+			// the attribute info is shared with the data source, change a copy
+			original := *attr
+			original.Required = false
+			original.StringValidators = append(append([]validator.String{}, attr.StringValidators...),
+				stringvalidator.ExactlyOneOf(path.MatchRoot(writeOnly.TerraformName)))
+			result[i] = &original
+			writeOnly.StringValidators = append([]validator.String{}, attr.StringValidators...)
+		} else {
+			writeOnly.StringValidators = append(append([]validator.String{}, attr.StringValidators...),
+				stringvalidator.ConflictsWith(path.MatchRoot(attr.TerraformName)))
+		}
+		version := &AttributeInfo{
+			BaseType:            Int64,
+			TerraformName:       attr.TerraformName + writeOnlyVersionSuffix,
+			MarkdownDescription: fmt.Sprintf("The version of `%v`. Change it to send `%v` to the broker again.", writeOnly.TerraformName, writeOnly.TerraformName),
+			Type:                types.Int64Type,
+			TerraformType:       tftypes.Number,
+			Converter:           IntegerConverter{},
+			RequiresReplace:     attr.RequiresReplace,
+			WriteOnlyVersion:    true,
+		}
+		version.Description = version.MarkdownDescription
+		result = append(result, &writeOnly, version)
+	}
+	return result
+}
+
+// withWriteOnlyValues returns the data to send to the broker, the plan with the write-only arguments of the
+// configuration, which are null in plans and states. For updates, they are only sent if their version changed.
+func (r *brokerResource) withWriteOnlyValues(plan tftypes.Value, config tftypes.Value, state tftypes.Value) (tftypes.Value, error) {
+	planValues, err := copyObjectValues(plan)
+	if err != nil {
+		return plan, err
+	}
+	configValues := map[string]tftypes.Value{}
+	if err := config.As(&configValues); err != nil {
+		return plan, err
+	}
+	stateValues := map[string]tftypes.Value{}
+	if !state.IsNull() {
+		if err := state.As(&stateValues); err != nil {
+			return plan, err
+		}
+	}
+	changed := false
+	for _, attr := range r.attributes {
+		if !attr.WriteOnly {
+			continue
+		}
+		version := attr.TerraformName[:len(attr.TerraformName)-len(writeOnlySuffix)] + writeOnlyVersionSuffix
+		if !state.IsNull() && planValues[version].Equal(stateValues[version]) {
+			continue
+		}
+		if v, ok := configValues[attr.TerraformName]; ok && !v.IsNull() {
+			planValues[attr.TerraformName] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return plan, nil
+	}
+	return tftypes.NewValue(plan.Type(), planValues), nil
+}
@@ -0,0 +1,198 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func writeOnlyTestInputs() EntityInputs {
+	inputs := msgVpnTestInputs("msg_vpn_queue_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+	inputs.Attributes = append(inputs.Attributes, &AttributeInfo{BaseType: String, SempName: "password", TerraformName: "password", Sensitive: true,
+		Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}})
+	return inputs
+}
+
+func TestWriteOnlySecretAttributes(t *testing.T) {
+	for name, want := range map[string]bool{
+		"password":                               true,
+		"authentication_oauth_client_secret":     true,
+		"authentication_aws_secret_access_key":   true,
+		"authentication_kerberos_keytab_content": true,
+		"tls_server_cert_content":                true,
+		"replication_enabled_queue_behavior":     false,
+		"order_after_authorization_group_name":   false,
+		"authentication_http_header_value":       false,
+		"monkey_name":                            false,
+	} {
+		attr := &AttributeInfo{BaseType: String, TerraformName: name, Sensitive: true}
+		if got := hasWriteOnlyArgument(attr); got != want {
+			t.Errorf("expected a write-only argument for %v to be %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestWriteOnlySchema(t *testing.T) {
+	r := newBrokerResource(writeOnlyTestInputs())
+	writeOnly, ok := r.schema.Attributes["password_wo"].(schema.StringAttribute)
+	if !ok || !writeOnly.WriteOnly || !writeOnly.Sensitive || writeOnly.Required {
+		t.Errorf("expected an optional sensitive write-only password_wo, got %#v", r.schema.Attributes["password_wo"])
+	}
+	if _, ok := r.schema.Attributes["password_wo_version"].(schema.Int64Attribute); !ok {
+		t.Errorf("expected an Int64 password_wo_version, got %#v", r.schema.Attributes["password_wo_version"])
+	}
+	ds := newBrokerDataSource(writeOnlyTestInputs())
+	for _, name := range []string{"password", "password_wo", "password_wo_version"} {
+		if _, ok := ds.schema.Attributes[name]; ok {
+			t.Errorf("expected no %v in the data source", name)
+		}
+	}
+}
+
+func TestWriteOnlyRequiredArgument(t *testing.T) {
+	ctx := context.Background()
+	inputs := msgVpnTestInputs("msg_vpn_oauth_test", "/msgVpns/{msgVpnName}/queues/{queueName}")
+	clientSecret := &AttributeInfo{BaseType: String, SempName: "clientSecret", TerraformName: "client_secret", Sensitive: true, Required: true, RequiresReplace: true,
+		Type: types.StringType, TerraformType: tftypes.String, Converter: SimpleConverter[string]{TerraformType: tftypes.String}}
+	inputs.Attributes = append(inputs.Attributes, clientSecret)
+	r := newBrokerResource(inputs)
+	if !clientSecret.Required {
+		t.Error("expected the shared attribute info to be left unchanged")
+	}
+	original := r.schema.Attributes["client_secret"].(schema.StringAttribute)
+	if original.Required || !original.Optional {
+		t.Errorf("expected client_secret to be optional, got %#v", original)
+	}
+	version := r.schema.Attributes["client_secret_wo_version"].(schema.Int64Attribute)
+	if len(version.PlanModifiers) != 1 || version.PlanModifiers[0].Description(ctx) != int64planmodifier.RequiresReplace().Description(ctx) {
+		t.Errorf("expected client_secret_wo_version to require replacement, got %v", version.PlanModifiers)
+	}
+
+	for _, test := range []struct {
+		name         string
+		clientSecret tftypes.Value
+		writeOnly    tftypes.Value
+		wantError    bool
+	}{
+		{name: "attribute", clientSecret: tftypes.NewValue(tftypes.String, "secret"), writeOnly: tftypes.NewValue(tftypes.String, nil)},
+		{name: "write-only argument", clientSecret: tftypes.NewValue(tftypes.String, nil), writeOnly: tftypes.NewValue(tftypes.String, "secret")},
+		{name: "none", clientSecret: tftypes.NewValue(tftypes.String, nil), writeOnly: tftypes.NewValue(tftypes.String, nil), wantError: true},
+		{name: "both", clientSecret: tftypes.NewValue(tftypes.String, "secret"), writeOnly: tftypes.NewValue(tftypes.String, "secret"), wantError: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := tfsdk.Config{Schema: r.schema, Raw: tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+				"msg_vpn_name":             tftypes.NewValue(tftypes.String, "default"),
+				"queue_name":               tftypes.NewValue(tftypes.String, "q1"),
+				"client_secret":            test.clientSecret,
+				"client_secret_wo":         test.writeOnly,
+				"client_secret_wo_version": tftypes.NewValue(tftypes.Number, nil),
+			})}
+			var diagnostics diag.Diagnostics
+			for _, name := range []string{"client_secret", "client_secret_wo"} {
+				var value types.String
+				diagnostics.Append(config.GetAttribute(ctx, path.Root(name), &value)...)
+				for _, v := range r.schema.Attributes[name].(schema.StringAttribute).Validators {
+					response := &validator.StringResponse{}
+					v.ValidateString(ctx, validator.StringRequest{Path: path.Root(name), PathExpression: path.MatchRoot(name), ConfigValue: value, Config: config}, response)
+					diagnostics.Append(response.Diagnostics...)
+				}
+			}
+			if diagnostics.HasError() != test.wantError {
+				t.Errorf("unexpected diagnostics %v", diagnostics)
+			}
+		})
+	}
+}
+
+func TestWriteOnlyArguments(t *testing.T) {
+	ctx := context.Background()
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		_, _ = w.Write([]byte(`{"data":{"msgVpnName":"default","queueName":"q1"},"meta":{"responseCode":200}}`))
+	}))
+	t.Cleanup(server.Close)
+	r := brokerResource(newBrokerResource(writeOnlyTestInputs()))
+	r.client = configureTestProvider(t, fakeBrokerProviderConfig(server.URL, true))
+	value := func(password tftypes.Value, version int64) tftypes.Value {
+		return tftypes.NewValue(r.schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+			"msg_vpn_name":        tftypes.NewValue(tftypes.String, "default"),
+			"queue_name":          tftypes.NewValue(tftypes.String, "q1"),
+			"password":            tftypes.NewValue(tftypes.String, nil),
+			"password_wo":         password,
+			"password_wo_version": tftypes.NewValue(tftypes.Number, version),
+		})
+	}
+	secret := tftypes.NewValue(tftypes.String, "secret")
+	null := tftypes.NewValue(tftypes.String, nil)
+
+	createResponse := &resource.CreateResponse{State: tfsdk.State{Schema: r.schema}}
+	r.Create(ctx, resource.CreateRequest{
+		Config: tfsdk.Config{Schema: r.schema, Raw: value(secret, 1)},
+		Plan:   tfsdk.Plan{Schema: r.schema, Raw: value(null, 1)},
+	}, createResponse)
+	if createResponse.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics %v", createResponse.Diagnostics)
+	}
+	if !strings.Contains(bodies[0], `"password":"secret"`) {
+		t.Errorf("expected the write-only password to be sent, got %v", bodies[0])
+	}
+	if !createResponse.State.Raw.Equal(value(null, 1)) {
+		t.Errorf("expected the write-only password not to be stored, got %v", createResponse.State.Raw)
+	}
+
+	for _, test := range []struct {
+		name     string
+		version  int64
+		wantSent bool
+	}{
+		{name: "same version", version: 1},
+		{name: "new version", version: 2, wantSent: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			bodies = nil
+			updateResponse := &resource.UpdateResponse{State: tfsdk.State{Schema: r.schema}}
+			r.Update(ctx, resource.UpdateRequest{
+				Config: tfsdk.Config{Schema: r.schema, Raw: value(secret, test.version)},
+				Plan:   tfsdk.Plan{Schema: r.schema, Raw: value(null, test.version)},
+				State:  tfsdk.State{Schema: r.schema, Raw: value(null, 1)},
+			}, updateResponse)
+			if updateResponse.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics %v", updateResponse.Diagnostics)
+			}
+			if sent := strings.Contains(bodies[0], `"password":"secret"`); sent != test.wantSent {
+				t.Errorf("expected password sent %v, got %v", test.wantSent, bodies[0])
+			}
+		})
+	}
+}
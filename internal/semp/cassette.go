@@ -0,0 +1,231 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+type CassetteMode string
+
+const (
+	// CassetteRecord sends requests to the broker and appends them with their responses to the cassette
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay serves responses from the cassette without contacting the broker
+	CassetteReplay CassetteMode = "replay"
+
+	CassetteEnv     = "SOLACEBROKER_SEMP_CASSETTE"
+	CassetteModeEnv = "SOLACEBROKER_SEMP_CASSETTE_MODE"
+)
+
+// interaction is one recorded request and its response, a cassette file has one interaction per line
+type interaction struct {
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	RequestBody  string            `json:"requestBody,omitempty"`
+	Status       int               `json:"status"`
+	Header       map[string]string `json:"header,omitempty"`
+	ResponseBody string            `json:"responseBody"`
+}
+
+// recordedHeaders are the response headers that are recorded, all others may carry session state
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Cassette records the SEMP traffic of the client to a file or replays it from there. Authorization headers are never
+// recorded and secrets in request and response bodies are redacted.
+func Cassette(path string, mode CassetteMode) Option {
+	return func(client *Client) {
+		client.cassette = &cassette{path: path, mode: mode}
+	}
+}
+
+// cassetteFromEnv returns the cassette configured by environment variables, if any. The default mode is replay.
+func cassetteFromEnv() *cassette {
+	path := os.Getenv(CassetteEnv)
+	if path == "" {
+		return nil
+	}
+	mode := CassetteMode(strings.ToLower(os.Getenv(CassetteModeEnv)))
+	if mode == "" {
+		mode = CassetteReplay
+	}
+	return &cassette{path: path, mode: mode}
+}
+
+type cassette struct {
+	path     string
+	mode     CassetteMode
+	redactor *redactor
+
+	mu     sync.Mutex
+	loaded bool
+	err    error
+	// replay holds the recorded interactions that have not been replayed yet by method and path
+	replay map[string][]interaction
+	// last holds the last recorded interaction by method and path, it is replayed once the others are used up
+	last map[string]interaction
+}
+
+// transport returns a round tripper that records the traffic sent through next or replays it
+func (c *cassette) transport(next http.RoundTripper) http.RoundTripper {
+	return &cassetteTransport{cassette: c, next: next}
+}
+
+type cassetteTransport struct {
+	cassette *cassette
+	next     http.RoundTripper
+}
+
+func (t *cassetteTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	switch t.cassette.mode {
+	case CassetteRecord:
+		response, err := t.next.RoundTrip(request)
+		if err != nil {
+			return nil, err
+		}
+		responseBody, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		response.Body = io.NopCloser(bytes.NewReader(responseBody))
+		i := interaction{
+			Method:       request.Method,
+			Path:         t.cassette.redactor.redactString(request.URL.RequestURI()),
+			RequestBody:  t.cassette.redactor.redactBody(requestBody, request.Header.Get("Content-Type")),
+			Status:       response.StatusCode,
+			ResponseBody: t.cassette.redactor.redactBody(responseBody, response.Header.Get("Content-Type")),
+		}
+		for _, name := range recordedHeaders {
+			if value := response.Header.Get(name); value != "" {
+				if i.Header == nil {
+					i.Header = map[string]string{}
+				}
+				i.Header[name] = value
+			}
+		}
+		if err := t.cassette.record(i); err != nil {
+			return nil, err
+		}
+		return response, nil
+	case CassetteReplay:
+		// paths are recorded with secrets masked
+		i, err := t.cassette.next(request.Method, t.cassette.redactor.redactString(request.URL.RequestURI()))
+		if err != nil {
+			return nil, err
+		}
+		response := &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+			StatusCode:    i.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(i.ResponseBody)),
+			ContentLength: int64(len(i.ResponseBody)),
+			Request:       request,
+		}
+		for name, value := range i.Header {
+			response.Header.Set(name, value)
+		}
+		return response, nil
+	}
+	return nil, fmt.Errorf("unsupported SEMP cassette mode %q, use %q or %q", t.cassette.mode, CassetteRecord, CassetteReplay)
+}
+
+func (c *cassette) record(i interaction) error {
+	line, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not record SEMP traffic: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not record SEMP traffic: %w", err)
+	}
+	return nil
+}
+
+// next returns the recorded response to a request. Identical requests are answered in the order they were recorded.
+func (c *cassette) next(method, path string) (interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		c.loaded = true
+		c.err = c.load()
+	}
+	if c.err != nil {
+		return interaction{}, c.err
+	}
+	key := method + " " + path
+	if recorded := c.replay[key]; len(recorded) > 0 {
+		c.replay[key] = recorded[1:]
+		return recorded[0], nil
+	}
+	if i, ok := c.last[key]; ok {
+		return i, nil
+	}
+	return interaction{}, fmt.Errorf("no recorded response for %v %v in SEMP cassette %v", method, path, c.path)
+}
+
+func (c *cassette) load() error {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return fmt.Errorf("could not replay SEMP traffic: %w", err)
+	}
+	defer file.Close()
+	c.replay = map[string][]interaction{}
+	c.last = map[string]interaction{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var i interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return fmt.Errorf("could not parse SEMP cassette %v line %d: %w", c.path, line, err)
+		}
+		key := i.Method + " " + i.Path
+		c.replay[key] = append(c.replay[key], i)
+		c.last[key] = i
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,122 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCassette(t *testing.T) {
+	var reads atomic.Int64
+	broker := newTestBroker(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = fmt.Fprintf(w, `{"data":{"msgVpnName":"default","maxConnectionCount":%d,"authenticationBasicPassword":"broker-secret"},"meta":{"responseCode":200}}`, reads.Add(1))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"meta":{"error":{"code":10,"description":"Already exists","status":"ALREADY_EXISTS"},"responseCode":400}}`))
+		}
+	})
+	path := filepath.Join(t.TempDir(), "semp.cassette")
+	ctx := context.Background()
+	run := func(client *Client) ([]map[string]any, error) {
+		var results []map[string]any
+		for i := 0; i < 2; i++ {
+			result, err := client.RequestWithoutBody(ctx, http.MethodGet, "/msgVpns/default")
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		_, err := client.RequestWithBody(ctx, http.MethodPost, "/msgVpns", map[string]any{"msgVpnName": "default", "authenticationBasicPassword": "client-secret"})
+		return results, err
+	}
+
+	recorder := NewClient(broker.URL, true, false, BasicAuth("admin", "topsecret"), Retries(0, 0, 0), RequestLimits(time.Minute, 0),
+		SensitiveAttributes("authenticationBasicPassword"), Cassette(path, CassetteRecord))
+	recorded, err := run(recorder)
+	var sempErr *Error
+	if !errors.As(err, &sempErr) || sempErr.Status != StatusAlreadyExists {
+		t.Fatalf("expected ALREADY_EXISTS, got %v", err)
+	}
+	broker.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"broker-secret", "client-secret", "topsecret", "YWRtaW46dG9wc2VjcmV0"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("cassette contains secret %q:\n%s", secret, content)
+		}
+	}
+	if n := strings.Count(string(content), "\n"); n != 3 {
+		t.Errorf("expected 3 recorded interactions, got %d", n)
+	}
+
+	// the broker is gone, the responses come from the cassette in the order they were recorded
+	t.Setenv(CassetteEnv, path)
+	replayer := NewClient(broker.URL, true, false, BasicAuth("admin", "topsecret"), Retries(0, 0, 0), RequestLimits(time.Minute, 0))
+	replayed, err := run(replayer)
+	if !errors.As(err, &sempErr) || sempErr.Status != StatusAlreadyExists {
+		t.Fatalf("expected replayed ALREADY_EXISTS, got %v", err)
+	}
+	for _, result := range recorded {
+		result["authenticationBasicPassword"] = redactedValue
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("replayed %v, recorded %v", replayed, recorded)
+	}
+	if _, err := replayer.RequestWithoutBody(ctx, http.MethodGet, "/msgVpns/other"); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected missing recording error, got %v", err)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		body        string
+		contentType string
+		want        string
+	}{
+		{`{"data":{"password":"a","name":"b"}}`, "application/json", `{"data":{"name":"b","password":"<redacted>"}}`},
+		{`{"data":[{"clientSecret":"a"},{"accessTokenExpiry":300}]}`, "application/json", `{"data":[{"clientSecret":"<redacted>"},{"accessTokenExpiry":300}]}`},
+		// names suggesting a secret are kept unless the attribute is sensitive
+		{`{"authenticationOauthClientTokenEndpoint":"https://idp/token","secretHint":"a"}`, "application/json", `{"authenticationOauthClientTokenEndpoint":"https://idp/token","secretHint":"a"}`},
+		{`{"access_token":"a","expires_in":300}`, "application/json", `{"access_token":"<redacted>","expires_in":300}`},
+		{`client_secret=a&grant_type=client_credentials`, "application/x-www-form-urlencoded", `client_secret=%3Credacted%3E&grant_type=client_credentials`},
+		{`not json`, "text/plain", `not json`},
+		{``, "", ``},
+	}
+	r := &redactor{}
+	r.setSensitiveNames([]string{"password", "clientSecret"})
+	for _, tt := range tests {
+		if got := r.redactBody([]byte(tt.body), tt.contentType); got != tt.want {
+			t.Errorf("redactBody(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -17,16 +17,19 @@
 package semp
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
@@ -39,22 +42,41 @@ var (
 	ErrBadRequest              = errors.New("bad request")
 	ErrInvalidPath             = errors.New("invalid path")
 	ErrProviderParametersError = errors.New("provider parameters error")
+	ErrReadOnly                = errors.New("changes are not allowed in read-only mode")
 )
 
-var firstRequest = true
-
 type Client struct {
 	*retryablehttp.Client
-	url                string
+	urls               []string
 	username           string
 	password           string
 	bearerToken        string
+	clientCertificate  *tls.Certificate
+	rootCAs            *x509.CertPool
+	tlsServerName      string
+	tlsMinVersion      uint16
+	proxy              func(*http.Request) (*url.URL, error)
+	requestHeaders     http.Header
+	hostHeader         string
+	tokenSource        *oauthTokenSource
+	cassette           *cassette
+	session            *session
+	pathTemplates      *pathTemplateMatcher
+	redactor           *redactor
+	stats              requestStats
 	retries            int64
 	retryMinInterval   time.Duration
 	retryMaxInterval   time.Duration
 	requestMinInterval time.Duration
 	requestTimeout     time.Duration
-	rateLimiter        <-chan time.Time
+	requestBurst       int64
+	rateLimiter        *rateLimiter
+	readOnly           bool
+	// requestSlots holds a value for each request in flight, limiting the number of concurrent requests
+	requestSlots chan struct{}
+	// activeNode is the index of the URL in urls requests are sent to
+	activeNode   atomic.Int64
+	failoverLock sync.Mutex
 }
 
 const (
@@ -62,11 +84,11 @@ const (
 	DefaultRetryMaxInterval = 30 * time.Second
 	DefaultRequestTimeout   = time.Minute
 	DefaultRequestInterval  = 100 * time.Millisecond
+	DefaultRequestBurst     = 1
+	DefaultMaxConcurrent    = 10
 	DefaultRetries          = 10
 )
 
-var Cookies = map[string]*http.Cookie{}
-
 type Option func(*Client)
 
 func BasicAuth(username, password string) Option {
@@ -82,6 +104,20 @@ func BearerToken(bearerToken string) Option {
 	}
 }
 
+func ClientCertificate(certificate tls.Certificate) Option {
+	return func(client *Client) {
+		client.clientCertificate = &certificate
+	}
+}
+
+func TLSSettings(rootCAs *x509.CertPool, serverName string, minVersion uint16) Option {
+	return func(client *Client) {
+		client.rootCAs = rootCAs
+		client.tlsServerName = serverName
+		client.tlsMinVersion = minVersion
+	}
+}
+
 func Retries(numRetries int64, retryMinInterval, retryMaxInterval time.Duration) Option {
 	return func(client *Client) {
 		client.retries = numRetries
@@ -97,201 +133,346 @@ func RequestLimits(requestTimeoutDuration, requestMinInterval time.Duration) Opt
 	}
 }
 
-func NewClient(url string, insecure_skip_verify bool, providerClient bool, options ...Option) *Client {
-	tr := &http.Transport{
-		TLSClientConfig:     &tls.Config{InsecureSkipVerify: insecure_skip_verify},
-		MaxIdleConnsPerHost: 10,
-		Proxy:               http.ProxyFromEnvironment,
+// RequestBurst sets how many requests may be sent in a row without waiting for the minimum request interval
+func RequestBurst(burst int64) Option {
+	return func(client *Client) {
+		client.requestBurst = burst
+	}
+}
+
+// MaxConcurrentRequests limits the number of requests in flight, the connection pool is sized accordingly
+func MaxConcurrentRequests(maxConcurrentRequests int64) Option {
+	return func(client *Client) {
+		client.requestSlots = make(chan struct{}, max(maxConcurrentRequests, 1))
+	}
+}
+
+// ReadOnly makes the client refuse all requests but GET, so that it cannot change the broker
+func ReadOnly(readOnly bool) Option {
+	return func(client *Client) {
+		client.readOnly = readOnly
 	}
+}
+
+func NewClient(url string, insecure_skip_verify bool, providerClient bool, options ...Option) *Client {
 	retryClient := retryablehttp.NewClient()
-	retryClient.HTTPClient.Transport = tr
 	if !providerClient {
 		retryClient.Logger = nil
 	}
 	client := &Client{
 		Client:           retryClient,
-		url:              url,
+		urls:             []string{url},
 		retries:          3,
 		retryMinInterval: time.Second,
 		retryMaxInterval: time.Second * 10,
+		requestSlots:     make(chan struct{}, DefaultMaxConcurrent),
+		redactor:         &redactor{},
 	}
 	for _, o := range options {
 		o(client)
 	}
+	client.redactor.addSecrets(client.password, client.bearerToken)
+	for _, values := range client.requestHeaders {
+		client.redactor.addSecrets(values...)
+	}
+	if client.tokenSource != nil {
+		client.redactor.addSecrets(client.tokenSource.clientSecret)
+	}
+	if providerClient {
+		retryClient.Logger = redactingLogger{redactor: client.redactor}
+	}
+	serverName := client.tlsServerName
+	if serverName == "" && client.hostHeader != "" {
+		serverName = hostName(client.hostHeader)
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure_skip_verify,
+		RootCAs:            client.rootCAs,
+		ServerName:         serverName,
+		MinVersion:         client.tlsMinVersion,
+	}
+	if client.clientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*client.clientCertificate}
+	}
+	tr := &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConnsPerHost: cap(client.requestSlots),
+		MaxConnsPerHost:     cap(client.requestSlots),
+		Proxy:               http.ProxyFromEnvironment,
+	}
+	if client.proxy != nil {
+		tr.Proxy = client.proxy
+	}
+	if client.tokenSource != nil {
+		// the token endpoint is trusted, reached and authenticated to like the broker, but it is another host
+		tokenTLSConfig := tlsConfig.Clone()
+		tokenTLSConfig.ServerName = ""
+		client.tokenSource.httpClient.Transport = &http.Transport{TLSClientConfig: tokenTLSConfig, Proxy: tr.Proxy}
+	}
+	var transport http.RoundTripper = tr
+	if len(client.requestHeaders) > 0 || client.hostHeader != "" {
+		transport = &headerTransport{next: transport, headers: client.requestHeaders, host: client.hostHeader}
+	}
+	if client.cassette == nil {
+		client.cassette = cassetteFromEnv()
+	}
+	if client.cassette != nil {
+		client.cassette.redactor = client.redactor
+		transport = client.cassette.transport(transport)
+		if client.tokenSource != nil {
+			client.tokenSource.httpClient.Transport = client.cassette.transport(client.tokenSource.httpClient.Transport)
+		}
+	}
+	retryClient.HTTPClient.Transport = transport
+	retryClient.CheckRetry = checkRetry
+	retryClient.RequestLogHook = countRetries
+	retryClient.Backoff = retryBackoff
+	// return the last response once retries are exhausted so that the SEMP error details are reported
+	retryClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
 	client.Client.RetryMax = int(client.retries)
 	client.Client.RetryWaitMin = client.retryMinInterval
 	client.Client.RetryWaitMax = client.retryMaxInterval
 	client.HTTPClient.Timeout = client.requestTimeout
-	client.HTTPClient.Jar, _ = cookiejar.New(nil)
-	if client.requestMinInterval > 0 {
-		client.rateLimiter = time.NewTicker(client.requestMinInterval).C
+	if client.tokenSource != nil {
+		client.tokenSource.httpClient.Timeout = client.requestTimeout
+	}
+	if client.session != nil {
+		client.session.jar = newSessionJar()
+		client.HTTPClient.Jar = client.session.jar
 	} else {
-		ch := make(chan time.Time)
-		// closing the channel will make receiving from the channel non-blocking (the value received will be the
-		//  zero value)
-		close(ch)
-		client.rateLimiter = ch
+		client.HTTPClient.Jar, _ = cookiejar.New(nil)
+	}
+	client.rateLimiter = newRateLimiter(client.requestMinInterval, client.requestBurst)
+	if client.rateLimiter != nil {
+		retryClient.HTTPClient.Transport = &throttlingTransport{next: transport, limiter: client.rateLimiter}
 	}
-	firstRequest = true
 	return client
 }
 
 func (c *Client) RequestWithBody(ctx context.Context, method, url string, body any) (map[string]any, error) {
+	ctx = c.MaskLogs(ctx)
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
-	request, err := http.NewRequestWithContext(ctx, method, c.url+url, bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	tflog.Debug(ctx, fmt.Sprintf("===== %v to %v =====", request.Method, request.URL))
-	rawBody, err := c.doRequest(request)
+	request, rawBody, httpStatus, err := c.send(ctx, method, url, data)
 	if err != nil {
-		return nil, err
+		return nil, c.redactor.redactError(err)
 	}
-	return parseResponseAsObject(ctx, request, rawBody)
+	result, err := parseResponseAsObject(ctx, request, httpStatus, rawBody)
+	return result, c.redactor.redactError(err)
 }
 
-func (c *Client) doRequest(request *http.Request) ([]byte, error) {
-	if !firstRequest {
-		// the value doesn't matter, it is waiting for the value that matters
-		<-c.rateLimiter
-	} else {
-		// only skip rate limiter for the first request
-		firstRequest = false
+// doRequest sends a request and returns the response body and HTTP status, which is either 200 or 400 as the broker
+// reports the details of bad requests in the response metadata
+func (c *Client) doRequest(request *http.Request) (rawBody []byte, httpStatus int, err error) {
+	template := c.pathTemplates.match(c.relativePath(request.URL))
+	request, span := c.startRequestSpan(request, template)
+	request, retry := withRetryRequest(request)
+	var statusCode int
+	var sempStatus string
+	var rateLimitWait time.Duration
+	var sent time.Time
+	defer func() {
+		// errors of the HTTP client include the request URL, which can hold the opaque password
+		endRequestSpan(span, statusCode, sempStatus, retry.retries, rateLimitWait, c.redactor.redactError(err))
+		if !sent.IsZero() {
+			c.stats.record(request.Method, template, time.Since(sent), retry.retries, rateLimitWait, err != nil || sempStatus != "")
+		}
+	}()
+	select {
+	case c.requestSlots <- struct{}{}:
+		defer func() { <-c.requestSlots }()
+	case <-request.Context().Done():
+		return nil, 0, request.Context().Err()
+	}
+	waitStart := time.Now()
+	if err := c.rateLimiter.Wait(request.Context()); err != nil {
+		return nil, 0, err
 	}
-	if request.Method != http.MethodGet {
+	rateLimitWait = time.Since(waitStart)
+	sent = time.Now()
+	if request.Method != http.MethodGet && request.Header.Get("Content-Type") == "" {
 		request.Header.Set("Content-Type", "application/json")
 	}
-	if c.bearerToken != "" {
-		request.Header.Set("Authorization", "Bearer "+c.bearerToken)
-	} else if c.username != "" {
-		request.SetBasicAuth(c.username, c.password)
-	} else {
-		return nil, fmt.Errorf("either username or bearer token must be provided to access the broker")
+	token, err := c.authorize(request)
+	if err != nil {
+		return nil, 0, err
 	}
-	var response *http.Response
-	var err error
-	response, err = c.StandardClient().Do(request)
+	response, err := c.StandardClient().Do(request)
 	if err != nil || response == nil {
-		return nil, err
+		return nil, 0, err
+	}
+	if response.StatusCode == http.StatusUnauthorized && token != "" {
+		// The token may have been revoked or expired early and the session may have expired, retry once with a new one
+		response.Body.Close()
+		if c.tokenSource != nil {
+			tflog.Debug(request.Context(), fmt.Sprintf("Access token rejected during %v to %v, retrying with a new token", request.Method, request.URL))
+			c.tokenSource.Invalidate(token)
+		} else {
+			tflog.Debug(request.Context(), fmt.Sprintf("Session expired during %v to %v, logging in again", request.Method, request.URL))
+			c.invalidateSession(token)
+		}
+		request, err = cloneRequest(request)
+		if err != nil {
+			return nil, 0, err
+		}
+		// the cookie jar adds the current cookies again
+		request.Header.Del("Cookie")
+		if _, err = c.authorize(request); err != nil {
+			return nil, 0, err
+		}
+		response, err = c.StandardClient().Do(request)
+		if err != nil || response == nil {
+			return nil, 0, err
+		}
 	}
 	defer response.Body.Close()
-	rawBody, err := io.ReadAll(response.Body)
-	if err != nil || (response.StatusCode != http.StatusOK && response.StatusCode != http.StatusBadRequest) {
-		return nil, fmt.Errorf("could not perform request: status %v (%v) during %v to %v, response body:\n%s", response.StatusCode, response.Status, request.Method, request.URL, rawBody)
+	statusCode = response.StatusCode
+	rawBody, err = io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not perform request: status %v (%v) during %v to %v: %w", response.StatusCode, response.Status, request.Method, request.URL, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		// the broker may still have provided error details in the response metadata
+		var r sempResponse
+		_ = json.Unmarshal(rawBody, &r)
+		if r.Meta != nil && r.Meta.Error != nil {
+			sempStatus = r.Meta.Error.Status
+		}
+		if response.StatusCode != http.StatusBadRequest {
+			return nil, 0, newError(request, response.StatusCode, r.Meta, rawBody)
+		}
 	}
 	if _, err := io.Copy(io.Discard, response.Body); err != nil {
-		return nil, fmt.Errorf("response processing error: during %v to %v", request.Method, request.URL)
+		return nil, 0, fmt.Errorf("response processing error: during %v to %v", request.Method, request.URL)
+	}
+	return rawBody, response.StatusCode, nil
+}
+
+// authorize sets the Authorization header of the request and returns the access token or session cookie used, if any
+func (c *Client) authorize(request *http.Request) (string, error) {
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token(request.Context())
+		if err != nil {
+			return "", err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		return token, nil
+	}
+	if c.bearerToken != "" {
+		request.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	} else if c.username != "" {
+		if c.session != nil {
+			cookie, err := c.sessionCookie(request.Context(), request.URL)
+			if err != nil || cookie != "" {
+				// the cookie jar adds the session cookie to the request
+				return cookie, err
+			}
+		}
+		request.SetBasicAuth(c.username, c.password)
+	} else if c.clientCertificate == nil {
+		// with client certificate authentication the TLS handshake authenticates the request
+		return "", fmt.Errorf("either username, bearer token, OAuth client credentials or client certificate must be provided to access the broker")
 	}
-	return rawBody, nil
+	return "", nil
 }
 
-func parseResponseAsObject(ctx context.Context, request *http.Request, dataResponse []byte) (map[string]any, error) {
-	data := map[string]any{}
-	err := json.Unmarshal(dataResponse, &data)
+func cloneRequest(request *http.Request) (*http.Request, error) {
+	clone := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func parseResponseAsObject(ctx context.Context, request *http.Request, httpStatus int, dataResponse []byte) (map[string]any, error) {
+	var r sempResponse
+	err := json.Unmarshal(dataResponse, &r)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse response body from %v to %v, response body was:\n%s", request.Method, request.URL, dataResponse)
 	}
-	rawData, ok := data["data"]
-	if ok {
+	if r.Data != nil {
 		// Valid data
-		data, _ = rawData.(map[string]any)
+		data := map[string]any{}
+		if err := json.Unmarshal(r.Data, &data); err != nil {
+			return nil, fmt.Errorf("could not parse response data from %v to %v, response body was:\n%s", request.Method, request.URL, dataResponse)
+		}
 		return data, nil
-	} else {
+	}
+	if r.Meta != nil {
 		// Analize response metadata details
-		rawData, ok = data["meta"]
-		if ok {
-			data, _ = rawData.(map[string]any)
-			if data["responseCode"].(float64) == http.StatusOK {
-				// this is valid response for delete
-				return nil, nil
-			}
-			description := data["error"].(map[string]interface{})["description"].(string)
-			status := data["error"].(map[string]interface{})["status"].(string)
-			if status == "NOT_FOUND" {
-				// resource not found is a special type we want to return
-				return nil, fmt.Errorf("request failed from %v to %v, %v, %v, %w", request.Method, request.URL, description, status, ErrResourceNotFound)
-			}
-			tflog.Error(ctx, fmt.Sprintf("SEMP request returned %v, %v", description, status))
-			return nil, fmt.Errorf("request failed for %v using %v, %v, %v", request.URL, request.Method, description, status)
+		if r.Meta.Error == nil && r.Meta.ResponseCode == http.StatusOK {
+			// this is valid response for delete
+			return nil, nil
 		}
+		sempErr := newError(request, httpStatus, r.Meta, dataResponse)
+		if sempErr.Status != StatusNotFound {
+			tflog.Error(ctx, fmt.Sprintf("SEMP request returned %v, %v", sempErr.Description, sempErr.Status))
+		}
+		return nil, sempErr
 	}
 	return nil, fmt.Errorf("could not parse response details from %v to %v, response body was:\n%s", request.Method, request.URL, dataResponse)
 }
 
-func parseResponseForGenerator(c *Client, ctx context.Context, basePath string, method string, request *http.Request, dataResponse []byte, appendToResult []map[string]any) ([]map[string]any, error) {
-	data := map[string]any{}
-	err := json.Unmarshal(dataResponse, &data)
+func parseResponseForGenerator(c *Client, ctx context.Context, basePath string, method string, request *http.Request, httpStatus int, dataResponse []byte, appendToResult []map[string]any) ([]map[string]any, error) {
+	var r sempResponse
+	err := json.Unmarshal(dataResponse, &r)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse response body from %v to %v, response body was:\n%s", request.Method, request.URL, dataResponse)
 	}
-	responseData := []map[string]any{}
-	rawData, ok := data["data"]
-	if ok {
+	if r.Data != nil {
+		var rawData any
+		if err := json.Unmarshal(r.Data, &rawData); err != nil {
+			return nil, fmt.Errorf("could not parse response data from %v to %v, response body was:\n%s", request.Method, request.URL, dataResponse)
+		}
+		responseData := []map[string]any{}
 		switch rawData := rawData.(type) {
-		case []interface{}:
-			responseDataRaw := rawData
-			for _, t := range responseDataRaw {
-				responseData = append(responseData, t.(map[string]any))
+		case []any:
+			for _, t := range rawData {
+				if item, ok := t.(map[string]any); ok {
+					responseData = append(responseData, item)
+				}
 			}
-		case map[string]interface{}:
-			responseDataRaw := rawData
-			responseData = append(responseData, responseDataRaw)
+		case map[string]any:
+			responseData = append(responseData, rawData)
 		}
-		metaData, hasMeta := data["meta"]
 		appendToResult = append(appendToResult, responseData...)
-		if hasMeta {
-			pageData, hasPaging := metaData.(map[string]any)["paging"]
-			if hasPaging {
-				nextPage := fmt.Sprint(pageData.(map[string]any)["nextPageUri"])
-				nextPageUrl := strings.Split(nextPage, basePath)
-				return c.RequestWithoutBodyForGenerator(ctx, basePath, method, nextPageUrl[1], appendToResult)
+		if r.Meta != nil && r.Meta.Paging != nil {
+			nextPageUrl := strings.Split(r.Meta.Paging.NextPageUri, basePath)
+			if len(nextPageUrl) < 2 {
+				return nil, fmt.Errorf("unexpected next page URI %v from %v to %v", r.Meta.Paging.NextPageUri, request.Method, request.URL)
 			}
+			return c.RequestWithoutBodyForGenerator(ctx, basePath, method, nextPageUrl[1], appendToResult)
 		}
 		return appendToResult, nil
-	} else {
-		rawData, ok = data["meta"]
-		if ok {
-			data, _ = rawData.(map[string]any)
-			description := data["error"].(map[string]interface{})["description"].(string)
-			status := data["error"].(map[string]interface{})["status"].(string)
-			if status == "NOT_FOUND" {
-				// resource not found is a special type we want to return
-				return nil, fmt.Errorf("%v, %v, %w", description, status, ErrResourceNotFound)
-			}
-			if status == "INVALID_PATH" {
-				// resource not found is a special type we want to return
-				return nil, fmt.Errorf("%v, %v, %w", description, status, ErrInvalidPath)
-			}
-			return nil, fmt.Errorf("%v, %v, %w", description, status, ErrBadRequest)
-		}
+	}
+	if r.Meta != nil {
+		return nil, newError(request, httpStatus, r.Meta, dataResponse)
 	}
 	return nil, nil
 }
 
 func (c *Client) RequestWithoutBody(ctx context.Context, method, url string) (map[string]interface{}, error) {
-	request, err := http.NewRequestWithContext(ctx, method, c.url+url, nil)
-	if err != nil {
-		return nil, err
-	}
-	tflog.Debug(ctx, fmt.Sprintf("===== %v to %v =====", request.Method, request.URL))
-	rawBody, err := c.doRequest(request)
+	ctx = c.MaskLogs(ctx)
+	request, rawBody, httpStatus, err := c.send(ctx, method, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, c.redactor.redactError(err)
 	}
-	return parseResponseAsObject(ctx, request, rawBody)
+	result, err := parseResponseAsObject(ctx, request, httpStatus, rawBody)
+	return result, c.redactor.redactError(err)
 }
 
 func (c *Client) RequestWithoutBodyForGenerator(ctx context.Context, basePath string, method string, url string, appendToResult []map[string]any) ([]map[string]interface{}, error) {
-	request, err := http.NewRequestWithContext(ctx, method, c.url+url, nil)
-	if err != nil {
-		return nil, err
-	}
-	rawBody, err := c.doRequest(request)
+	ctx = c.MaskLogs(ctx)
+	request, rawBody, httpStatus, err := c.send(ctx, method, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, c.redactor.redactError(err)
 	}
-	return parseResponseForGenerator(c, ctx, basePath, method, request, rawBody, appendToResult)
+	result, err := parseResponseForGenerator(c, ctx, basePath, method, request, httpStatus, rawBody, appendToResult)
+	return result, c.redactor.redactError(err)
 }
@@ -0,0 +1,149 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestBroker(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func aboutApiHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"data":{"platform":"VMR","sempVersion":"2.40"},"meta":{"responseCode":200}}`))
+}
+
+func TestTLSSettings(t *testing.T) {
+	server := newTestBroker(t, aboutApiHandler)
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	loadedPool, err := LoadCACertificates(string(caPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		rootCAs    *x509.CertPool
+		serverName string
+		minVersion uint16
+		wantErr    bool
+	}{
+		{"TrustedCA", pool, "", tls.VersionTLS12, false},
+		{"TrustedCAFromPEM", loadedPool, "", tls.VersionTLS12, false},
+		{"ServerNameOverride", pool, "example.com", tls.VersionTLS13, false},
+		{"WrongServerName", pool, "wrong.example.org", tls.VersionTLS12, true},
+		{"UntrustedCA", x509.NewCertPool(), "", tls.VersionTLS12, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(server.URL, false, false,
+				BasicAuth("admin", "admin"),
+				TLSSettings(tt.rootCAs, tt.serverName, tt.minVersion),
+				Retries(0, 0, 0))
+			result, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RequestWithoutBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && result["platform"] != "VMR" {
+				t.Errorf("RequestWithoutBody() result = %v", result)
+			}
+		})
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	const limit = 3
+	var inFlight, maxInFlight atomic.Int64
+	broker := newTestBroker(t, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		aboutApiHandler(w, r)
+	})
+	client := NewClient(broker.URL, true, false, BasicAuth("admin", "admin"), RequestLimits(time.Minute, 0), MaxConcurrentRequests(limit))
+	var wg sync.WaitGroup
+	for i := 0; i < 4*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := maxInFlight.Load(); n != limit {
+		t.Errorf("expected %d concurrent requests, got %d", limit, n)
+	}
+
+	// a request waiting for a free slot gives up when its context is done
+	for i := 0; i < limit; i++ {
+		client.requestSlots <- struct{}{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/about/api"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to be cancelled, got %v", err)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	var changes atomic.Int64
+	broker := newTestBroker(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			changes.Add(1)
+		}
+		aboutApiHandler(w, r)
+	})
+	client := NewClient(broker.URL, true, false, BasicAuth("admin", "admin"), RequestLimits(time.Minute, 0), ReadOnly(true))
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api"); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodPost} {
+		if _, err := client.RequestWithBody(context.Background(), method, "/msgVpns/default", map[string]any{}); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("expected %v to be refused, got %v", method, err)
+		}
+	}
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodDelete, "/msgVpns/default"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected DELETE to be refused, got %v", err)
+	}
+	if n := changes.Load(); n != 0 {
+		t.Errorf("expected no changes to reach the broker, got %d", n)
+	}
+}
@@ -0,0 +1,84 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// credentialProcessTimeout limits how long a credential process may run
+const credentialProcessTimeout = time.Minute
+
+// Credentials are the credentials printed by a credential process as a JSON object
+type Credentials struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	BearerToken string `json:"bearer_token"`
+}
+
+// ReadSecretFile returns the content of a file holding a secret, without trailing line breaks
+func ReadSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read secret file: %w", err)
+	}
+	secret := strings.TrimRight(string(content), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("secret file %v is empty", path)
+	}
+	return secret, nil
+}
+
+// RunCredentialProcess runs a command through the shell and parses the credentials it prints to standard output
+func RunCredentialProcess(ctx context.Context, command string) (*Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialProcessTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// the output may hold credentials, only standard error is reported
+		return nil, fmt.Errorf("credential process failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var credentials Credentials
+	decoder := json.NewDecoder(&stdout)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&credentials); err != nil {
+		return nil, fmt.Errorf("credential process output is not a JSON object with username, password or bearer_token: %w", err)
+	}
+	if credentials.BearerToken != "" && (credentials.Username != "" || credentials.Password != "") {
+		return nil, fmt.Errorf("credential process must provide either username and password or bearer_token")
+	}
+	if credentials.BearerToken == "" && (credentials.Username == "" || credentials.Password == "") {
+		return nil, fmt.Errorf("credential process must provide both username and password, or bearer_token")
+	}
+	return &credentials, nil
+}
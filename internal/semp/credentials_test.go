@@ -0,0 +1,85 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestReadSecretFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"TrailingNewline", "s3cret \n", "s3cret ", false},
+		{"WindowsNewline", "s3cret\r\n", "s3cret", false},
+		{"Empty", "\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadSecretFile(path)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ReadSecretFile() = %q, %v, want %q, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if _, err := ReadSecretFile(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("ReadSecretFile() of missing file succeeded")
+	}
+}
+
+func TestRunCredentialProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	tests := []struct {
+		name    string
+		command string
+		want    Credentials
+		wantErr bool
+	}{
+		{"BasicAuth", `echo '{"username":"admin","password":"s3cret"}'`, Credentials{Username: "admin", Password: "s3cret"}, false},
+		{"BearerToken", `printf '{"bearer_token":"abc"}'`, Credentials{BearerToken: "abc"}, false},
+		{"MissingPassword", `echo '{"username":"admin"}'`, Credentials{}, true},
+		{"BothMethods", `echo '{"username":"admin","password":"s3cret","bearer_token":"abc"}'`, Credentials{}, true},
+		{"UnknownField", `echo '{"token":"abc"}'`, Credentials{}, true},
+		{"NotJSON", `echo s3cret`, Credentials{}, true},
+		{"Failure", `echo failed >&2; exit 3`, Credentials{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RunCredentialProcess(context.Background(), tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunCredentialProcess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("RunCredentialProcess() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
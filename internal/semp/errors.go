@@ -0,0 +1,105 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SEMP error status values reported by the broker in meta.error.status
+const (
+	StatusAlreadyExists    = "ALREADY_EXISTS"
+	StatusNotFound         = "NOT_FOUND"
+	StatusInvalidPath      = "INVALID_PATH"
+	StatusNotAllowed       = "NOT_ALLOWED"
+	StatusInvalidParameter = "INVALID_PARAMETER"
+	StatusMissingParameter = "MISSING_PARAMETER"
+	StatusUnauthorized     = "UNAUTHORIZED"
+)
+
+// Error is a failed SEMP request, either reported by the broker in the response metadata or signalled by the HTTP
+// status only. Use errors.As to inspect it; errors.Is matches ErrResourceNotFound, ErrInvalidPath and ErrBadRequest.
+type Error struct {
+	// HTTPStatus is the status of the HTTP response, ResponseCode the responseCode of the response metadata if any
+	HTTPStatus   int
+	ResponseCode int
+	Status       string
+	Description  string
+	Method       string
+	Path         string
+	// RequestID is the X-Request-ID header of the failed request
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("request failed for %v using %v, status %v (%v), response body:\n%s", e.Path, e.Method, e.HTTPStatus, http.StatusText(e.HTTPStatus), e.Description)
+	}
+	return fmt.Sprintf("request failed for %v using %v, %v, %v", e.Path, e.Method, e.Description, e.Status)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrResourceNotFound:
+		return e.Status == StatusNotFound
+	case ErrInvalidPath:
+		return e.Status == StatusInvalidPath
+	case ErrBadRequest:
+		return e.HTTPStatus == http.StatusBadRequest || e.ResponseCode == http.StatusBadRequest
+	}
+	return false
+}
+
+type responseMeta struct {
+	ResponseCode int `json:"responseCode"`
+	Error        *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+		Status      string `json:"status"`
+	} `json:"error"`
+	Paging *struct {
+		Cursor      string `json:"cursor"`
+		NextPageUri string `json:"nextPageUri"`
+	} `json:"paging"`
+}
+
+type sempResponse struct {
+	Data json.RawMessage `json:"data"`
+	Meta *responseMeta   `json:"meta"`
+}
+
+// newError builds an Error from the response metadata, falling back to the raw response body if the broker did not
+// provide error details
+func newError(request *http.Request, httpStatus int, meta *responseMeta, rawBody []byte) *Error {
+	e := &Error{
+		HTTPStatus:  httpStatus,
+		Method:      request.Method,
+		Path:        request.URL.Path,
+		Description: string(rawBody),
+		RequestID:   request.Header.Get(RequestIDHeader),
+	}
+	if meta != nil {
+		e.ResponseCode = meta.ResponseCode
+		if meta.Error != nil {
+			e.Status = meta.Error.Status
+			e.Description = meta.Error.Description
+		}
+	}
+	return e
+}
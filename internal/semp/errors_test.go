@@ -0,0 +1,116 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name         string
+		httpStatus   int
+		body         string
+		want         Error
+		notFound     bool
+		invalidPath  bool
+		isBadRequest bool
+	}{
+		{
+			name:       "already exists",
+			httpStatus: http.StatusBadRequest,
+			body:       `{"meta":{"error":{"code":10,"description":"Already exists","status":"ALREADY_EXISTS"},"responseCode":400}}`,
+			want: Error{HTTPStatus: http.StatusBadRequest, ResponseCode: http.StatusBadRequest, Status: StatusAlreadyExists,
+				Description: "Already exists", Method: http.MethodPost, Path: "/msgVpns"},
+			isBadRequest: true,
+		},
+		{
+			name:       "not found",
+			httpStatus: http.StatusBadRequest,
+			body:       `{"meta":{"error":{"code":6,"description":"Could not find match","status":"NOT_FOUND"},"responseCode":400}}`,
+			want: Error{HTTPStatus: http.StatusBadRequest, ResponseCode: http.StatusBadRequest, Status: StatusNotFound,
+				Description: "Could not find match", Method: http.MethodPost, Path: "/msgVpns"},
+			notFound:     true,
+			isBadRequest: true,
+		},
+		{
+			name:       "not allowed",
+			httpStatus: http.StatusForbidden,
+			body:       `{"meta":{"error":{"code":2,"description":"Permission denied","status":"NOT_ALLOWED"},"responseCode":403}}`,
+			want: Error{HTTPStatus: http.StatusForbidden, ResponseCode: http.StatusForbidden, Status: StatusNotAllowed,
+				Description: "Permission denied", Method: http.MethodPost, Path: "/msgVpns"},
+		},
+		{
+			name:       "no error details",
+			httpStatus: http.StatusNotFound,
+			body:       `not found`,
+			want: Error{HTTPStatus: http.StatusNotFound, Description: "not found",
+				Method: http.MethodPost, Path: "/msgVpns"},
+		},
+		{
+			// the HTTP status and the SEMP response code are reported separately
+			name:       "error details with HTTP status OK",
+			httpStatus: http.StatusOK,
+			body:       `{"meta":{"error":{"code":6,"description":"Could not find match","status":"NOT_FOUND"},"responseCode":400}}`,
+			want: Error{HTTPStatus: http.StatusOK, ResponseCode: http.StatusBadRequest, Status: StatusNotFound,
+				Description: "Could not find match", Method: http.MethodPost, Path: "/msgVpns"},
+			notFound:     true,
+			isBadRequest: true,
+		},
+		{
+			name:       "malformed error details",
+			httpStatus: http.StatusBadRequest,
+			body:       `{"meta":{"responseCode":400}}`,
+			want: Error{HTTPStatus: http.StatusBadRequest, ResponseCode: http.StatusBadRequest,
+				Description: `{"meta":{"responseCode":400}}`, Method: http.MethodPost, Path: "/msgVpns"},
+			isBadRequest: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestID string
+			broker := newTestBroker(t, func(w http.ResponseWriter, r *http.Request) {
+				requestID = r.Header.Get(RequestIDHeader)
+				w.WriteHeader(tt.httpStatus)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			client := NewClient(broker.URL, true, false, BasicAuth("admin", "admin"), Retries(0, 0, 0))
+			_, err := client.RequestWithBody(context.Background(), http.MethodPost, "/msgVpns", map[string]any{})
+			var sempErr *Error
+			if !errors.As(err, &sempErr) {
+				t.Fatalf("expected a SEMP error, got %v", err)
+			}
+			want := tt.want
+			want.RequestID = requestID
+			if *sempErr != want {
+				t.Errorf("got %+v, want %+v", *sempErr, want)
+			}
+			if errors.Is(err, ErrResourceNotFound) != tt.notFound {
+				t.Errorf("errors.Is(err, ErrResourceNotFound) = %v", !tt.notFound)
+			}
+			if errors.Is(err, ErrInvalidPath) != tt.invalidPath {
+				t.Errorf("errors.Is(err, ErrInvalidPath) = %v", !tt.invalidPath)
+			}
+			if errors.Is(err, ErrBadRequest) != tt.isBadRequest {
+				t.Errorf("errors.Is(err, ErrBadRequest) = %v", !tt.isBadRequest)
+			}
+		})
+	}
+}
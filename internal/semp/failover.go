@@ -0,0 +1,208 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redundancyQuery asks the monitor API of a node for its redundancy role
+const redundancyQuery = "/?select=redundancyRole"
+
+// FailoverURLs adds the SEMP URLs of the other nodes of an HA redundancy group. Requests are sent to the node that is
+// currently active, and fail over to the mate if the active node cannot be reached or is no longer active.
+func FailoverURLs(urls ...string) Option {
+	return func(client *Client) {
+		client.urls = append(client.urls, urls...)
+	}
+}
+
+// activeURL returns the index and the SEMP URL of the node requests are currently sent to
+func (c *Client) activeURL() (int, string) {
+	node := int(c.activeNode.Load())
+	return node, c.urls[node]
+}
+
+// ActiveURL returns the SEMP URL of the node requests are currently sent to
+func (c *Client) ActiveURL() string {
+	_, url := c.activeURL()
+	return url
+}
+
+// send sends a request to the active node, failing over to another node of the redundancy group if required
+func (c *Client) send(ctx context.Context, method, path string, body []byte) (*http.Request, []byte, int, error) {
+	if c.readOnly && method != http.MethodGet {
+		return nil, nil, 0, fmt.Errorf("%w: refused %v to %v", ErrReadOnly, method, path)
+	}
+	for attempt := 1; ; attempt++ {
+		node, url := c.activeURL()
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		request, err := http.NewRequestWithContext(ctx, method, url+path, bodyReader)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		tflog.Debug(ctx, fmt.Sprintf("===== %v to %v =====", request.Method, request.URL))
+		rawBody, httpStatus, err := c.doRequest(request)
+		if len(c.urls) == 1 || attempt == len(c.urls) || ctx.Err() != nil {
+			return request, rawBody, httpStatus, err
+		}
+		reason, rejected := failoverReason(method, rawBody, err)
+		if reason == "" || !c.failover(ctx, node, reason, rejected) {
+			return request, rawBody, httpStatus, err
+		}
+	}
+}
+
+// failoverReason returns why a request may have to be sent to another node, or an empty string if it should not. A
+// POST is only sent again if the failed node cannot have applied it. Requests rejected by the broker are only sent
+// again if the rejection says the node is not active, and the node turns out to be standby.
+func failoverReason(method string, rawBody []byte, err error) (reason string, rejected bool) {
+	var sempErr *Error
+	switch {
+	case err == nil:
+		var r sempResponse
+		if json.Unmarshal(rawBody, &r) == nil && r.Meta != nil && r.Meta.Error != nil && isNotActive(r.Meta.Error.Description) {
+			return fmt.Sprintf("request rejected, %v", r.Meta.Error.Description), true
+		}
+		return "", false
+	case errors.As(err, &sempErr):
+		if sempErr.HTTPStatus == http.StatusServiceUnavailable {
+			return "node is unavailable", false
+		}
+		if isNotActive(sempErr.Description) {
+			return fmt.Sprintf("request rejected, %v", sempErr.Description), true
+		}
+		return "", false
+	case isDialError(err):
+		return fmt.Sprintf("could not connect, %v", err), false
+	case method != http.MethodPost && !isCertificateError(err):
+		return fmt.Sprintf("connection failed, %v", err), false
+	}
+	return "", false
+}
+
+// isNotActive returns whether a SEMP error description says the node rejected the request for not being active
+func isNotActive(description string) bool {
+	description = strings.ToLower(description)
+	return strings.Contains(description, "standby") || strings.Contains(description, "not active")
+}
+
+// failover switches to the next active node after failed, it returns false if there is none. If the request was
+// rejected, it only fails over if the failed node is standby.
+func (c *Client) failover(ctx context.Context, failed int, reason string, rejected bool) bool {
+	c.failoverLock.Lock()
+	defer c.failoverLock.Unlock()
+	if int(c.activeNode.Load()) != failed {
+		// another request has already failed over
+		return true
+	}
+	if rejected && c.nodeRole(ctx, c.urls[failed]) != roleStandby {
+		return false
+	}
+	for i := 1; i < len(c.urls); i++ {
+		candidate := (failed + i) % len(c.urls)
+		if c.nodeRole(ctx, c.urls[candidate]) == roleActive {
+			tflog.Warn(ctx, fmt.Sprintf("Failing over from %v to %v: %v", c.urls[failed], c.urls[candidate], reason))
+			c.activeNode.Store(int64(candidate))
+			return true
+		}
+	}
+	tflog.Warn(ctx, fmt.Sprintf("No active node to fail over to from %v: %v", c.urls[failed], reason))
+	return false
+}
+
+// SelectActiveNode probes the nodes of the redundancy group and sends requests to the first one that is active. If no
+// node reports being active, requests are sent to the first node.
+func (c *Client) SelectActiveNode(ctx context.Context) {
+	if len(c.urls) < 2 {
+		return
+	}
+	c.failoverLock.Lock()
+	defer c.failoverLock.Unlock()
+	for i, url := range c.urls {
+		if c.nodeRole(ctx, url) == roleActive {
+			c.activeNode.Store(int64(i))
+			return
+		}
+	}
+	tflog.Warn(ctx, fmt.Sprintf("No node of the redundancy group reports being active, sending requests to %v", c.urls[0]))
+	c.activeNode.Store(0)
+}
+
+// nodeRole is the redundancy role of a node, it is unknown if the node cannot be reached or does not report it
+type nodeRole int
+
+const (
+	roleUnknown nodeRole = iota
+	roleActive
+	roleStandby
+)
+
+// nodeRole queries the redundancyRole of a node through the monitor API. Nodes whose role cannot be read, for example
+// because the request is not authorized, are not considered active.
+func (c *Client) nodeRole(ctx context.Context, url string) nodeRole {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, monitorURL(url)+redundancyQuery, nil)
+	if err != nil {
+		return roleUnknown
+	}
+	if _, err := c.authorize(request); err != nil {
+		return roleUnknown
+	}
+	// not retried, the node is either available now or another one is tried
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Node %v is not reachable, %v", url, err))
+		return roleUnknown
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		tflog.Debug(ctx, fmt.Sprintf("Could not read the redundancy role of node %v, status %v", url, response.Status))
+		return roleUnknown
+	}
+	var r struct {
+		Data struct {
+			RedundancyRole string `json:"redundancyRole"`
+		} `json:"data"`
+	}
+	rawBody, _ := io.ReadAll(response.Body)
+	_ = json.Unmarshal(rawBody, &r)
+	switch strings.ToLower(r.Data.RedundancyRole) {
+	case "active":
+		return roleActive
+	case "standby":
+		return roleStandby
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Unknown redundancy role %q of node %v", r.Data.RedundancyRole, url))
+	return roleUnknown
+}
+
+// monitorURL returns the monitor API URL corresponding to a config API URL
+func monitorURL(url string) string {
+	return strings.TrimSuffix(url, "/config") + "/monitor"
+}
@@ -0,0 +1,184 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// haNode is a node of a redundancy group, standby nodes reject configuration changes
+type haNode struct {
+	*httptest.Server
+	active atomic.Bool
+	// unauthorized nodes reject the query of their redundancy role
+	unauthorized atomic.Bool
+	requests     atomic.Int64
+	// probes counts the queries of the redundancy role
+	probes atomic.Int64
+}
+
+func newHANode(t *testing.T, active bool) *haNode {
+	t.Helper()
+	n := &haNode{}
+	n.active.Store(active)
+	n.Server = newTestBroker(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		role := "standby"
+		if n.active.Load() {
+			role = "active"
+		}
+		if r.URL.Path == "/monitor/" {
+			n.probes.Add(1)
+			if n.unauthorized.Load() {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"data":{"redundancyRole":%q},"meta":{"responseCode":200}}`, role)
+			return
+		}
+		n.requests.Add(1)
+		if r.URL.Path == "/invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"meta":{"error":{"code":11,"description":"Invalid attribute","status":"INVALID_PARAMETER"},"responseCode":400}}`))
+			return
+		}
+		if role == "standby" && r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"meta":{"error":{"code":89,"description":"Operation not allowed on a standby node","status":"NOT_ALLOWED"},"responseCode":400}}`))
+			return
+		}
+		aboutApiHandler(w, r)
+	})
+	return n
+}
+
+func newHAClient(nodes ...*haNode) *Client {
+	var urls []string
+	for _, n := range nodes[1:] {
+		urls = append(urls, n.URL)
+	}
+	return NewClient(nodes[0].URL, true, false, BasicAuth("admin", "admin"), Retries(0, 0, 0),
+		RequestLimits(time.Minute, 0), FailoverURLs(urls...))
+}
+
+func TestFailoverOnSwitchover(t *testing.T) {
+	primary := newHANode(t, true)
+	backup := newHANode(t, false)
+	client := newHAClient(primary, backup)
+	ctx := context.Background()
+	if _, err := client.RequestWithBody(ctx, http.MethodPatch, "/msgVpns/default", map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the redundancy group switches over, the next change is sent to the backup
+	primary.active.Store(false)
+	backup.active.Store(true)
+	if _, err := client.RequestWithBody(ctx, http.MethodPatch, "/msgVpns/default", map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RequestWithBody(ctx, http.MethodPost, "/msgVpns", map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := primary.requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests to the primary, got %d", n)
+	}
+	if n := backup.requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests to the backup, got %d", n)
+	}
+}
+
+func TestFailoverOnConnectionError(t *testing.T) {
+	primary := newHANode(t, true)
+	backup := newHANode(t, true)
+	client := newHAClient(primary, backup)
+	primary.Close()
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if _, err := client.RequestWithBody(context.Background(), method, "/msgVpns", map[string]any{}); err != nil {
+			t.Fatalf("%v failed: %v", method, err)
+		}
+	}
+	if n := backup.requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests to the backup, got %d", n)
+	}
+}
+
+func TestFailoverWithoutActiveNode(t *testing.T) {
+	primary := newHANode(t, false)
+	backup := newHANode(t, false)
+	client := newHAClient(primary, backup)
+	_, err := client.RequestWithBody(context.Background(), http.MethodPatch, "/msgVpns/default", map[string]any{})
+	if err == nil {
+		t.Fatal("expected the change to be rejected")
+	}
+	if n := backup.requests.Load(); n != 0 {
+		t.Errorf("expected no requests to the standby backup, got %d", n)
+	}
+}
+
+func TestSelectActiveNode(t *testing.T) {
+	primary := newHANode(t, false)
+	backup := newHANode(t, true)
+	client := newHAClient(primary, backup)
+	client.SelectActiveNode(context.Background())
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/msgVpns/default"); err != nil {
+		t.Fatal(err)
+	}
+	if n := primary.requests.Load(); n != 0 {
+		t.Errorf("expected no requests to the standby primary, got %d", n)
+	}
+	if n := backup.requests.Load(); n != 1 {
+		t.Errorf("expected 1 request to the active backup, got %d", n)
+	}
+}
+
+func TestFailoverSkipsNodesWithUnknownRole(t *testing.T) {
+	primary := newHANode(t, false)
+	unauthorized := newHANode(t, true)
+	unauthorized.unauthorized.Store(true)
+	backup := newHANode(t, true)
+	client := newHAClient(primary, unauthorized, backup)
+	if _, err := client.RequestWithBody(context.Background(), http.MethodPatch, "/msgVpns/default", map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := unauthorized.requests.Load(); n != 0 {
+		t.Errorf("expected no requests to the node with an unknown role, got %d", n)
+	}
+	if n := backup.requests.Load(); n != 1 {
+		t.Errorf("expected 1 request to the active backup, got %d", n)
+	}
+}
+
+func TestNoFailoverOnRejectionByActiveNode(t *testing.T) {
+	primary := newHANode(t, true)
+	backup := newHANode(t, true)
+	client := newHAClient(primary, backup)
+	if _, err := client.RequestWithBody(context.Background(), http.MethodPatch, "/invalid", map[string]any{}); err == nil {
+		t.Fatal("expected the request to be rejected")
+	}
+	if n := backup.requests.Load(); n != 0 {
+		t.Errorf("expected no requests to the backup, got %d", n)
+	}
+	if n := primary.probes.Load() + backup.probes.Load(); n != 0 {
+		t.Errorf("expected no redundancy role queries, got %d", n)
+	}
+}
@@ -0,0 +1,133 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// maxTokenRefreshMargin is how long before expiry a cached token is refreshed at the latest
+const maxTokenRefreshMargin = 30 * time.Second
+
+// oauthTokenSource obtains access tokens using the OAuth2 client credentials grant and caches them until shortly
+// before they expire
+type oauthTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func OAuthClientCredentials(tokenURL, clientID, clientSecret string, scopes []string) Option {
+	return func(client *Client) {
+		client.tokenSource = &oauthTokenSource{
+			tokenURL:     tokenURL,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			scopes:       scopes,
+			// the transport is set by NewClient, from the TLS and proxy settings of the client
+			httpClient: &http.Client{},
+			now:        time.Now,
+		}
+	}
+}
+
+// Token returns the cached access token, or fetches a new one if there is none or it is about to expire
+func (ts *oauthTokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token != "" && (ts.refreshAt.IsZero() || ts.now().Before(ts.refreshAt)) {
+		return ts.token, nil
+	}
+	return ts.fetchToken(ctx)
+}
+
+// Invalidate drops the cached token if it is still the one that has been rejected
+func (ts *oauthTokenSource) Invalidate(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token == token {
+		ts.token = ""
+	}
+}
+
+func (ts *oauthTokenSource) fetchToken(ctx context.Context) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(ts.scopes) > 0 {
+		form.Set("scope", strings.Join(ts.scopes, " "))
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(ts.clientID), url.QueryEscape(ts.clientSecret))
+	tflog.Debug(ctx, fmt.Sprintf("===== Requesting OAuth access token from %v =====", ts.tokenURL))
+	issuedAt := ts.now()
+	response, err := ts.httpClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("could not obtain OAuth access token from %v: %w", ts.tokenURL, err)
+	}
+	defer response.Body.Close()
+	rawBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not obtain OAuth access token from %v: %w", ts.tokenURL, err)
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(rawBody, &token); err != nil {
+		return "", fmt.Errorf("could not parse OAuth token response from %v: status %v", ts.tokenURL, response.Status)
+	}
+	if response.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("could not obtain OAuth access token from %v: status %v, %v %v", ts.tokenURL, response.Status, token.Error, token.ErrorDescription)
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported OAuth token type %v from %v", token.TokenType, ts.tokenURL)
+	}
+	ts.token = token.AccessToken
+	ts.refreshAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		ts.refreshAt = issuedAt.Add(lifetime - min(maxTokenRefreshMargin, lifetime/2))
+	}
+	return ts.token, nil
+}
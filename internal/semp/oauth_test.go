@@ -0,0 +1,167 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testTokenServer issues numbered tokens and remembers which of them the broker should still accept
+type testTokenServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	issued    int
+	expiresIn int
+	revoked   map[string]bool
+	lastScope string
+}
+
+func newTestTokenServer(t *testing.T, expiresIn int) *testTokenServer {
+	t.Helper()
+	ts := newUnstartedTestTokenServer(t, expiresIn)
+	ts.Start()
+	return ts
+}
+
+func newUnstartedTestTokenServer(t *testing.T, expiresIn int) *testTokenServer {
+	t.Helper()
+	ts := &testTokenServer{expiresIn: expiresIn, revoked: map[string]bool{}}
+	ts.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client" || clientSecret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		ts.mu.Lock()
+		ts.issued++
+		ts.lastScope = r.FormValue("scope")
+		token := fmt.Sprintf("token-%d", ts.issued)
+		ts.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":%d}`, token, ts.expiresIn)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *testTokenServer) brokerHandler(w http.ResponseWriter, r *http.Request) {
+	ts.mu.Lock()
+	valid := r.Header.Get("Authorization") == fmt.Sprintf("Bearer token-%d", ts.issued) && !ts.revoked[r.Header.Get("Authorization")]
+	ts.mu.Unlock()
+	if !valid {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	aboutApiHandler(w, r)
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	tokenServer := newTestTokenServer(t, 300)
+	broker := newTestBroker(t, tokenServer.brokerHandler)
+	client := NewClient(broker.URL, true, false,
+		OAuthClientCredentials(tokenServer.URL, "client", "secret", []string{"semp", "admin"}),
+		Retries(0, 0, 0))
+	now := time.Now()
+	client.tokenSource.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// The token is cached across requests
+	for i := 0; i < 3; i++ {
+		if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/about/api"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tokenServer.issued != 1 {
+		t.Errorf("expected 1 token to be issued, got %d", tokenServer.issued)
+	}
+	if tokenServer.lastScope != "semp admin" {
+		t.Errorf("expected scope \"semp admin\", got %q", tokenServer.lastScope)
+	}
+
+	// The token is refreshed shortly before it expires
+	now = now.Add(269 * time.Second)
+	if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/about/api"); err != nil {
+		t.Fatal(err)
+	}
+	if tokenServer.issued != 1 {
+		t.Errorf("expected token not to be refreshed yet, got %d tokens issued", tokenServer.issued)
+	}
+	now = now.Add(2 * time.Second)
+	if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/about/api"); err != nil {
+		t.Fatal(err)
+	}
+	if tokenServer.issued != 2 {
+		t.Errorf("expected token to be refreshed, got %d tokens issued", tokenServer.issued)
+	}
+
+	// A rejected token is replaced and the request retried once
+	tokenServer.mu.Lock()
+	tokenServer.revoked["Bearer token-2"] = true
+	tokenServer.mu.Unlock()
+	if _, err := client.RequestWithBody(ctx, http.MethodPut, "/about/api", map[string]any{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if tokenServer.issued != 3 {
+		t.Errorf("expected a new token after 401, got %d tokens issued", tokenServer.issued)
+	}
+}
+
+func TestOAuthClientCredentialsInvalidClient(t *testing.T) {
+	tokenServer := newTestTokenServer(t, 300)
+	broker := newTestBroker(t, tokenServer.brokerHandler)
+	client := NewClient(broker.URL, true, false,
+		OAuthClientCredentials(tokenServer.URL, "client", "wrong", nil),
+		Retries(0, 0, 0))
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api"); err == nil {
+		t.Fatal("expected error for invalid client credentials")
+	}
+}
+
+func TestOAuthClientCredentialsPrivateCA(t *testing.T) {
+	tokenServer := newUnstartedTestTokenServer(t, 300)
+	tokenServer.StartTLS()
+	broker := newTestBroker(t, tokenServer.brokerHandler)
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(tokenServer.Certificate())
+	tests := []struct {
+		name    string
+		options []Option
+		wantErr bool
+	}{
+		{name: "trusted CA", options: []Option{TLSSettings(rootCAs, "", tls.VersionTLS12)}},
+		{name: "system trust store", options: []Option{TLSSettings(nil, "", tls.VersionTLS12)}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := append(test.options, OAuthClientCredentials(tokenServer.URL, "client", "secret", nil), Retries(0, 0, 0))
+			client := NewClient(broker.URL, false, false, options...)
+			_, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api")
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
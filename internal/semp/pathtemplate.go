@@ -0,0 +1,97 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"strings"
+)
+
+// PathTemplates sets the SEMP path templates of the broker objects, such as
+// /msgVpns/{msgVpnName}/queues/{queueName}, used to report requests by object type rather than by object. The
+// templates of the collections containing the objects are derived from them.
+func PathTemplates(templates ...string) Option {
+	return func(client *Client) {
+		client.pathTemplates = newPathTemplateMatcher(templates)
+	}
+}
+
+// pathTemplate is a path template split into segments, identifier segments are enclosed in braces
+type pathTemplate struct {
+	template string
+	segments []string
+	literals int
+}
+
+// pathTemplateMatcher finds the path template of a SEMP path
+type pathTemplateMatcher struct {
+	// templates by number of segments
+	templates map[int][]pathTemplate
+}
+
+func newPathTemplateMatcher(templates []string) *pathTemplateMatcher {
+	m := &pathTemplateMatcher{templates: map[int][]pathTemplate{}}
+	known := map[string]bool{}
+	add := func(template string) {
+		if template == "" || known[template] {
+			return
+		}
+		known[template] = true
+		t := pathTemplate{template: template, segments: strings.Split(strings.Trim(template, "/"), "/")}
+		for _, s := range t.segments {
+			if !strings.HasPrefix(s, "{") {
+				t.literals++
+			}
+		}
+		m.templates[len(t.segments)] = append(m.templates[len(t.segments)], t)
+	}
+	for _, template := range templates {
+		add(template)
+		if i := strings.LastIndex(template, "/"); i > 0 && strings.HasPrefix(template[i+1:], "{") {
+			add(template[:i])
+		}
+	}
+	return m
+}
+
+// match returns the template of an escaped path relative to the SEMP base path. If several templates match, the one
+// with the most literal segments is used, so /msgVpns/{msgVpnName}/queues is preferred over a template with an
+// identifier in place of queues. Paths without a matching template are returned unchanged.
+func (m *pathTemplateMatcher) match(path string) string {
+	if m == nil {
+		return path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var best *pathTemplate
+	for i, t := range m.templates[len(segments)] {
+		if (best == nil || t.literals > best.literals) && t.matches(segments) {
+			best = &m.templates[len(segments)][i]
+		}
+	}
+	if best == nil {
+		return path
+	}
+	return best.template
+}
+
+func (t pathTemplate) matches(segments []string) bool {
+	for i, s := range t.segments {
+		if !strings.HasPrefix(s, "{") && s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
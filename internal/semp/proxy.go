@@ -0,0 +1,115 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Proxy sends SEMP requests through the proxy at proxyURL instead of the proxy set by the HTTP_PROXY and HTTPS_PROXY
+// environment variables, except for the hosts in the comma-separated noProxy list. If proxyURL is empty, the proxy from
+// the environment is used with noProxy instead of NO_PROXY.
+func Proxy(proxyURL, noProxy string) Option {
+	return func(client *Client) {
+		config := httpproxy.FromEnvironment()
+		if proxyURL != "" {
+			config.HTTPProxy = proxyURL
+			config.HTTPSProxy = proxyURL
+		}
+		if noProxy != "" {
+			config.NoProxy = noProxy
+		}
+		proxyFunc := config.ProxyFunc()
+		client.proxy = func(request *http.Request) (*url.URL, error) {
+			return proxyFunc(request.URL)
+		}
+	}
+}
+
+// RequestHeaders adds headers to every SEMP request and overrides the Host header if host is set. The host also
+// becomes the TLS server name unless one is set explicitly.
+func RequestHeaders(headers map[string]string, host string) Option {
+	return func(client *Client) {
+		client.requestHeaders = http.Header{}
+		for name, value := range headers {
+			client.requestHeaders.Set(name, value)
+		}
+		client.hostHeader = host
+	}
+}
+
+// ParseProxyURL validates a proxy URL
+func ParseProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" || u.Host == "" {
+		return fmt.Errorf("invalid proxy URL %v, must be http://, https:// or socks5:// followed by host and port", u.Redacted())
+	}
+	return nil
+}
+
+// ParseRequestHeaders parses a comma-separated list of name=value pairs
+func ParseRequestHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, header := range strings.Split(s, ",") {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(header, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid request header %q, must be name=value", name)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// headerTransport adds the configured headers to every request
+type headerTransport struct {
+	next    http.RoundTripper
+	headers http.Header
+	host    string
+}
+
+func (t *headerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// a round tripper must not modify the request
+	request = request.Clone(request.Context())
+	for name, values := range t.headers {
+		request.Header[name] = values
+	}
+	if t.host != "" {
+		request.Host = t.host
+	}
+	return t.next.RoundTrip(request)
+}
+
+// hostName returns the host of a Host header without the port
+func hostName(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
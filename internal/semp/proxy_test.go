@@ -0,0 +1,92 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProxyAndRequestHeaders(t *testing.T) {
+	var proxied atomic.Int64
+	var host, tenant string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		host = r.Host
+		tenant = r.Header.Get("X-Tenant-Id")
+		aboutApiHandler(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	tests := []struct {
+		name        string
+		noProxy     string
+		hostHeader  string
+		wantHost    string
+		wantProxied int64
+		wantErr     bool
+	}{
+		{"Proxy", "", "", "broker.example.com", 1, false},
+		{"HostHeader", "", "gateway.example.com", "gateway.example.com", 1, false},
+		// broker.example.com cannot be reached directly
+		{"NoProxy", "example.com", "", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxied.Store(0)
+			host, tenant = "", ""
+			client := NewClient("http://broker.example.com/SEMP/v2/config", false, false,
+				BasicAuth("admin", "admin"),
+				Proxy(proxy.URL, tt.noProxy),
+				RequestHeaders(map[string]string{"x-tenant-id": "t1"}, tt.hostHeader),
+				Retries(0, 0, 0))
+			_, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RequestWithoutBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if proxied.Load() != tt.wantProxied {
+				t.Errorf("got %d proxied requests, want %d", proxied.Load(), tt.wantProxied)
+			}
+			if tt.wantProxied > 0 && (host != tt.wantHost || tenant != "t1") {
+				t.Errorf("got Host %q and tenant %q, want %q and t1", host, tenant, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestParseRequestHeaders(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"X-Tenant-Id=t1, X-Api-Key = k=1", map[string]string{"X-Tenant-Id": "t1", "X-Api-Key": "k=1"}, false},
+		{"X-Tenant-Id", nil, true},
+		{"=t1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRequestHeaders(tt.input)
+		if (err != nil) != tt.wantErr || !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRequestHeaders(%q) = %v, %v, want %v, wantErr %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
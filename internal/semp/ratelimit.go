@@ -0,0 +1,161 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// maxThrottleFactor limits how far the request rate is reduced below the configured rate
+	maxThrottleFactor = 32
+	// recoverySteps is the number of healthy responses needed to recover from one rate reduction
+	recoverySteps = 10
+	// a response is considered a latency spike if it takes latencySpikeFactor times longer than usual, and at least
+	// minLatencySpike
+	latencySpikeFactor = 4
+	minLatencySpike    = 500 * time.Millisecond
+	// latencyWeight is the weight of the latest response in the moving average of the latency
+	latencyWeight = 0.2
+)
+
+// rateLimiter is a token bucket that allows bursts of up to burst requests and refills at one token per interval.
+// The interval adapts to the broker: it is doubled when the broker is overloaded (AIMD multiplicative decrease of
+// the rate) and the rate is recovered in small steps as responses are healthy again (additive increase).
+type rateLimiter struct {
+	minInterval time.Duration
+	burst       float64
+	now         func() time.Time
+
+	mu             sync.Mutex
+	interval       time.Duration
+	tokens         float64
+	lastRefill     time.Time
+	lastDecrease   time.Time
+	averageLatency time.Duration
+}
+
+func newRateLimiter(minInterval time.Duration, burst int64) *rateLimiter {
+	if minInterval <= 0 {
+		return nil
+	}
+	burst = max(burst, 1)
+	return &rateLimiter{
+		minInterval: minInterval,
+		burst:       float64(burst),
+		now:         time.Now,
+		interval:    minInterval,
+		// the bucket starts full so that the first requests are not delayed
+		tokens: float64(burst),
+	}
+}
+
+// Wait blocks until a request may be sent or the context is done. A nil limiter does not limit requests.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	for {
+		l.mu.Lock()
+		l.refill()
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) * float64(l.interval))
+		l.mu.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *rateLimiter) refill() {
+	now := l.now()
+	if !l.lastRefill.IsZero() {
+		l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.lastRefill))/float64(l.interval))
+	}
+	l.lastRefill = now
+}
+
+// Observe adapts the request rate to the outcome of a request
+func (l *rateLimiter) Observe(ctx context.Context, statusCode int, latency time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	reason := ""
+	switch {
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable:
+		reason = fmt.Sprintf("status %v", statusCode)
+	case l.averageLatency > 0 && latency > minLatencySpike && latency > latencySpikeFactor*l.averageLatency:
+		reason = fmt.Sprintf("response time %v", latency)
+	}
+	if l.averageLatency == 0 {
+		l.averageLatency = latency
+	} else {
+		l.averageLatency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(l.averageLatency))
+	}
+	l.refill()
+	if reason != "" {
+		// responses to requests sent before the rate was reduced only count once
+		if l.now().Sub(l.lastDecrease) < l.interval {
+			return
+		}
+		l.lastDecrease = l.now()
+		interval := min(2*l.interval, maxThrottleFactor*l.minInterval)
+		if interval != l.interval {
+			l.interval = interval
+			tflog.Info(ctx, fmt.Sprintf("Broker is overloaded (%v), reducing request rate to one request every %v", reason, l.interval))
+		}
+		return
+	}
+	if l.interval > l.minInterval {
+		rate := float64(time.Second)/float64(l.interval) + float64(time.Second)/float64(l.minInterval)/recoverySteps
+		l.interval = max(time.Duration(float64(time.Second)/rate), l.minInterval)
+		if l.interval == l.minInterval {
+			tflog.Info(ctx, fmt.Sprintf("Broker has recovered, restoring request rate to one request every %v", l.interval))
+		}
+	}
+}
+
+// throttlingTransport reports the outcome of every request attempt, including retries, to the rate limiter
+type throttlingTransport struct {
+	next    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *throttlingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := t.limiter.now()
+	response, err := t.next.RoundTrip(request)
+	if err == nil {
+		t.limiter.Observe(request.Context(), response.StatusCode, t.limiter.now().Sub(start))
+	}
+	return response, err
+}
@@ -0,0 +1,106 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := newRateLimiter(time.Hour, 3)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("request %d of the burst was delayed: %v", i+1, err)
+		}
+	}
+	// the next request has to wait for an hour, it must give up as soon as the context is done
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled wait took %v", elapsed)
+	}
+	now = now.Add(time.Hour)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimiterAdapts(t *testing.T) {
+	const minInterval = 100 * time.Millisecond
+	l := newRateLimiter(minInterval, 1)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	l.Observe(ctx, http.StatusServiceUnavailable, 10*time.Millisecond)
+	if l.interval != 2*minInterval {
+		t.Fatalf("expected interval %v after 503, got %v", 2*minInterval, l.interval)
+	}
+	// further responses to requests sent at the old rate do not reduce the rate again
+	l.Observe(ctx, http.StatusTooManyRequests, 10*time.Millisecond)
+	if l.interval != 2*minInterval {
+		t.Fatalf("expected interval %v after repeated 429, got %v", 2*minInterval, l.interval)
+	}
+	now = now.Add(time.Second)
+	l.Observe(ctx, http.StatusTooManyRequests, 10*time.Millisecond)
+	if l.interval != 4*minInterval {
+		t.Fatalf("expected interval %v after 429, got %v", 4*minInterval, l.interval)
+	}
+	for i := 0; i < 100; i++ {
+		now = now.Add(time.Second)
+		l.Observe(ctx, http.StatusTooManyRequests, 10*time.Millisecond)
+	}
+	if l.interval != maxThrottleFactor*minInterval {
+		t.Fatalf("expected interval to be limited to %v, got %v", maxThrottleFactor*minInterval, l.interval)
+	}
+
+	// healthy responses restore the configured rate
+	for i := 0; i < 2*recoverySteps*maxThrottleFactor && l.interval > minInterval; i++ {
+		l.Observe(ctx, http.StatusOK, 10*time.Millisecond)
+	}
+	if l.interval != minInterval {
+		t.Fatalf("expected interval %v after recovery, got %v", minInterval, l.interval)
+	}
+
+	// a latency spike reduces the rate as well
+	now = now.Add(time.Second)
+	l.Observe(ctx, http.StatusOK, 2*time.Second)
+	if l.interval != 2*minInterval {
+		t.Fatalf("expected interval %v after latency spike, got %v", 2*minInterval, l.interval)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l := newRateLimiter(0, 1)
+	if l != nil {
+		t.Fatal("expected no limiter without a minimum request interval")
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.Observe(context.Background(), http.StatusTooManyRequests, time.Second)
+}
@@ -0,0 +1,322 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedValue replaces secrets in log messages, errors and recorded request and response bodies
+const redactedValue = "<redacted>"
+
+// minSecretLength is the length of the shortest secret value that is masked, masking shorter values would garble
+// unrelated text
+const minSecretLength = 3
+
+// authorizationPattern matches the credentials of an Authorization header
+var authorizationPattern = regexp.MustCompile(`(?i)(authorization\W{1,4}(?:basic|bearer)\s+)[^\s"',;]+`)
+
+// oauthSecretNames are the parameters of OAuth 2.0 token requests and responses holding secrets, see RFC 6749
+// sections 4.4 and 5.1
+var oauthSecretNames = []string{"access_token", "client_secret", "id_token", "refresh_token"}
+
+// oauthSecretPattern matches a JSON string member of an OAuth secret
+var oauthSecretPattern = namePattern(oauthSecretNames)
+
+// namePattern returns a regular expression matching a JSON string member with one of the names
+func namePattern(names []string) *regexp.Regexp {
+	var quoted []string
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	return regexp.MustCompile(`("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+}
+
+// SensitiveAttributes sets the SEMP names of the broker object attributes holding secrets, their values are redacted
+// from JSON bodies in addition to the OAuth secrets
+func SensitiveAttributes(names ...string) Option {
+	return func(client *Client) {
+		client.redactor.setSensitiveNames(names)
+	}
+}
+
+// RedactPatterns redacts the text matching any of the regular expressions from log messages, errors and recorded
+// traffic
+func RedactPatterns(patterns ...*regexp.Regexp) Option {
+	return func(client *Client) {
+		client.redactor.patterns = append(client.redactor.patterns, patterns...)
+	}
+}
+
+// ParseRedactPatterns compiles the regular expressions of RedactPatterns
+func ParseRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// redactor masks secrets, either known secret values or values of attributes known to hold secrets
+type redactor struct {
+	mu sync.RWMutex
+	// secrets holds the secret values and their escaped forms, longest first so that no part of a secret remains
+	secrets []string
+	// uses counts the additions of each secret form not removed yet
+	uses     map[string]int
+	patterns []*regexp.Regexp
+	// sensitivePattern matches a JSON string member of a sensitive attribute
+	sensitivePattern *regexp.Regexp
+	sensitiveNames   map[string]bool
+}
+
+func (r *redactor) setSensitiveNames(names []string) {
+	r.sensitiveNames = map[string]bool{}
+	var unique []string
+	for _, name := range names {
+		if !r.sensitiveNames[name] {
+			r.sensitiveNames[name] = true
+			unique = append(unique, name)
+		}
+	}
+	if len(unique) > 0 {
+		r.sensitivePattern = namePattern(unique)
+	}
+}
+
+// addSecrets adds secret values to be masked, as they are and as they appear in URLs and JSON strings. It returns
+// the forms added, to be passed to removeSecrets once the values are no longer used.
+func (r *redactor) addSecrets(values ...string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.uses == nil {
+		r.uses = map[string]int{}
+	}
+	var forms []string
+	added := false
+	for _, value := range values {
+		if len(value) < minSecretLength {
+			continue
+		}
+		jsonValue, _ := json.Marshal(value)
+		for _, form := range []string{value, url.PathEscape(value), url.QueryEscape(value), string(jsonValue[1 : len(jsonValue)-1])} {
+			forms = append(forms, form)
+			r.uses[form]++
+			if r.uses[form] == 1 {
+				r.secrets = append(r.secrets, form)
+				added = true
+			}
+		}
+	}
+	if added {
+		slices.SortFunc(r.secrets, func(a, b string) int { return len(b) - len(a) })
+	}
+	return forms
+}
+
+// removeSecrets removes the secret forms returned by addSecrets, a form is no longer masked once every addition of
+// it has been removed
+func (r *redactor) removeSecrets(forms []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, form := range forms {
+		r.uses[form]--
+		if r.uses[form] == 0 {
+			delete(r.uses, form)
+			r.secrets = slices.DeleteFunc(r.secrets, func(secret string) bool { return secret == form })
+		}
+	}
+}
+
+// redactString masks secrets in a log message or error text
+func (r *redactor) redactString(s string) string {
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	r.mu.RUnlock()
+	if r.sensitivePattern != nil {
+		s = r.sensitivePattern.ReplaceAllString(s, `${1}"`+redactedValue+`"`)
+	}
+	s = oauthSecretPattern.ReplaceAllString(s, `${1}"`+redactedValue+`"`)
+	s = authorizationPattern.ReplaceAllString(s, "${1}"+redactedValue)
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, redactedValue)
+	}
+	return s
+}
+
+// redactBody replaces the values of secret attributes in a JSON or form body and masks secrets in other bodies
+func (r *redactor) redactBody(body []byte, contentType string) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redactedValue
+		}
+		for k := range form {
+			if r.isSecretName(k) {
+				form.Set(k, redactedValue)
+			}
+		}
+		return r.redactString(form.Encode())
+	}
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// keep numbers as they are, large integers would lose precision as float64
+	decoder.UseNumber()
+	if len(body) == 0 || decoder.Decode(&value) != nil {
+		return r.redactString(string(body))
+	}
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.redactValue(value)); err != nil {
+		return r.redactString(string(body))
+	}
+	return r.redactString(strings.TrimSuffix(redacted.String(), "\n"))
+}
+
+func (r *redactor) redactValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for k, v := range value {
+			if _, isString := v.(string); isString && r.isSecretName(k) {
+				value[k] = redactedValue
+			} else {
+				value[k] = r.redactValue(v)
+			}
+		}
+	case []any:
+		for i, v := range value {
+			value[i] = r.redactValue(v)
+		}
+	}
+	return value
+}
+
+// isSecretName reports whether an attribute is sensitive or an OAuth secret
+func (r *redactor) isSecretName(name string) bool {
+	return r.sensitiveNames[name] || slices.Contains(oauthSecretNames, name)
+}
+
+// redactError returns an error with the secrets masked in its text and in the text of the errors it wraps. The
+// returned error still matches the same errors with errors.Is.
+func (r *redactor) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if sempErr, ok := err.(*Error); ok {
+		redacted := *sempErr
+		redacted.Path = r.redactString(sempErr.Path)
+		redacted.Description = r.redactString(sempErr.Description)
+		return &redacted
+	}
+	message := r.redactString(err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{message: message, wrapped: r.redactError(errors.Unwrap(err)), original: err}
+}
+
+// redactedError is an error with secrets masked in its text
+type redactedError struct {
+	message  string
+	wrapped  error
+	original error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.wrapped
+}
+
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.original, target)
+}
+
+// regexes returns the regular expressions matching secrets for masking log output
+func (r *redactor) regexes() []*regexp.Regexp {
+	regexes := []*regexp.Regexp{oauthSecretPattern, authorizationPattern}
+	if r.sensitivePattern != nil {
+		regexes = append(regexes, r.sensitivePattern)
+	}
+	return append(regexes, r.patterns...)
+}
+
+// AddSecrets adds secret values, like credentials, that are masked in log output, errors and recorded traffic from
+// now on
+func (c *Client) AddSecrets(values ...string) {
+	c.redactor.addSecrets(values...)
+}
+
+// AddOperationSecrets adds secret values, like the values of sensitive attributes in the data of an operation, that
+// are masked until the returned function is called at the end of the operation, so that the secrets of completed
+// operations do not accumulate
+func (c *Client) AddOperationSecrets(values ...string) func() {
+	forms := c.redactor.addSecrets(values...)
+	return func() { c.redactor.removeSecrets(forms) }
+}
+
+// Redact masks the secrets in a text
+func (c *Client) Redact(s string) string {
+	return c.redactor.redactString(s)
+}
+
+// RedactError masks the secrets in the text of an error and the errors it wraps
+func (c *Client) RedactError(err error) error {
+	return c.redactor.redactError(err)
+}
+
+// MaskLogs returns a context that masks the secrets known to the client in the messages and fields logged with it
+func (c *Client) MaskLogs(ctx context.Context) context.Context {
+	c.redactor.mu.RLock()
+	secrets := slices.Clone(c.redactor.secrets)
+	c.redactor.mu.RUnlock()
+	regexes := c.redactor.regexes()
+	ctx = tflog.MaskMessageStrings(ctx, secrets...)
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, secrets...)
+	ctx = tflog.MaskMessageRegexes(ctx, regexes...)
+	return tflog.MaskAllFieldValuesRegexes(ctx, regexes...)
+}
+
+// redactingLogger masks secrets in the log output of the retrying HTTP client
+type redactingLogger struct {
+	redactor *redactor
+}
+
+func (l redactingLogger) Printf(format string, args ...any) {
+	log.Print(l.redactor.redactString(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,108 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactString(t *testing.T) {
+	r := &redactor{}
+	r.setSensitiveNames([]string{"headerValue", "clientSecret"})
+	r.addSecrets("p@ss word/1", "ab")
+	r.patterns = []*regexp.Regexp{regexp.MustCompile(`tenant-[0-9]+`)}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"login with p@ss word/1 failed", "login with <redacted> failed"},
+		{"GET /msgVpns/p@ss%20word%2F1", "GET /msgVpns/<redacted>"},
+		{"form password=p%40ss+word%2F1", "form password=<redacted>"},
+		{`{"headerValue":"x\"y","name":"a"}`, `{"headerValue":"<redacted>","name":"a"}`},
+		{`{"clientSecret": "abc"}`, `{"clientSecret": "<redacted>"}`},
+		{`{"access_token":"abc","token_type":"Bearer"}`, `{"access_token":"<redacted>","token_type":"Bearer"}`},
+		{`{"authenticationOauthClientTokenEndpoint":"https://idp/token"}`, `{"authenticationOauthClientTokenEndpoint":"https://idp/token"}`},
+		{"Authorization: Bearer eyJhbGciOi.abc", "Authorization: Bearer <redacted>"},
+		{`"authorization":"Basic YWRtaW46YWRtaW4="`, `"authorization":"Basic <redacted>"`},
+		{"basic authentication failed", "basic authentication failed"},
+		{"queue of tenant-42", "queue of <redacted>"},
+		// secrets shorter than minSecretLength are not masked
+		{"abc", "abc"},
+	}
+	for _, tt := range tests {
+		if got := r.redactString(tt.input); got != tt.want {
+			t.Errorf("redactString(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	r := &redactor{}
+	r.addSecrets("topsecret")
+	sempErr := &Error{HTTPStatus: 400, Status: StatusNotFound, Description: "no object topsecret", Path: "/msgVpns/topsecret"}
+	err := r.redactError(fmt.Errorf("request failed: %w", sempErr))
+	if strings.Contains(err.Error(), "topsecret") {
+		t.Errorf("error reveals the secret: %v", err)
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if strings.Contains(e.Error(), "topsecret") {
+			t.Errorf("wrapped error reveals the secret: %v", e)
+		}
+	}
+	if !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("redacted error does not match ErrResourceNotFound")
+	}
+	var redacted *Error
+	if !errors.As(err, &redacted) || redacted.Path != "/msgVpns/<redacted>" {
+		t.Errorf("got %+v, want the SEMP error with the path redacted", redacted)
+	}
+	if sempErr.Path != "/msgVpns/topsecret" {
+		t.Errorf("original error was modified")
+	}
+	canceled := r.redactError(fmt.Errorf("topsecret: %w", context.Canceled))
+	if !errors.Is(canceled, context.Canceled) || strings.Contains(canceled.Error(), "topsecret") {
+		t.Errorf("got %v, want a redacted context.Canceled", canceled)
+	}
+	if plain := errors.New("no secret"); r.redactError(plain) != plain {
+		t.Errorf("errors without secrets must be returned as they are")
+	}
+}
+
+func TestOperationSecrets(t *testing.T) {
+	client := NewClient("https://localhost:1943/SEMP/v2/config", true, false, BasicAuth("admin", "shared-secret"))
+	first := client.AddOperationSecrets("operation-secret", "shared-secret")
+	second := client.AddOperationSecrets("operation-secret")
+	first()
+	if got := client.Redact("operation-secret"); got != redactedValue {
+		t.Errorf("got %q, want the secret of the running operation masked", got)
+	}
+	second()
+	if got := client.Redact("operation-secret"); got != "operation-secret" {
+		t.Errorf("got %q, want the secret of completed operations no longer masked", got)
+	}
+	if got := client.Redact("shared-secret"); got != redactedValue {
+		t.Errorf("got %q, want the credentials masked", got)
+	}
+	if len(client.redactor.secrets) != len(client.redactor.uses) {
+		t.Errorf("got %d secrets for %d uses", len(client.redactor.secrets), len(client.redactor.uses))
+	}
+}
@@ -0,0 +1,161 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type retryRequestKey struct{}
+
+// retryRequest identifies the request being retried, the response is not available for transport errors
+type retryRequest struct {
+	method string
+	url    string
+	// retries counts the attempts after the first one
+	retries int
+}
+
+func withRetryRequest(request *http.Request) (*http.Request, *retryRequest) {
+	r := &retryRequest{method: request.Method, url: request.URL.String()}
+	return request.WithContext(context.WithValue(request.Context(), retryRequestKey{}, r)), r
+}
+
+// countRetries is the request hook of the retrying client, it is called before every attempt
+func countRetries(_ retryablehttp.Logger, request *http.Request, attempt int) {
+	if r, ok := request.Context().Value(retryRequestKey{}).(*retryRequest); ok && attempt > 0 {
+		r.retries++
+	}
+}
+
+// checkRetry decides whether a SEMP request is retried. Requests are retried if the broker is temporarily unable
+// to serve them, but a POST is only replayed if the broker cannot have applied it yet, since creating an object
+// twice would fail with ALREADY_EXISTS.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	request, ok := ctx.Value(retryRequestKey{}).(*retryRequest)
+	if !ok {
+		request = &retryRequest{}
+	}
+	retry, reason := retryDecision(request.method, resp, err)
+	if retry {
+		tflog.Info(ctx, fmt.Sprintf("Retrying %v to %v: %v", request.method, request.url, reason))
+	} else if reason != "" {
+		tflog.Debug(ctx, fmt.Sprintf("Not retrying %v to %v: %v", request.method, request.url, reason))
+	}
+	return retry, nil
+}
+
+// retryDecision returns whether to retry and the reason for the decision, the reason is empty for successful requests
+func retryDecision(method string, resp *http.Response, err error) (bool, string) {
+	idempotent := method != http.MethodPost
+	if err != nil {
+		switch {
+		case isCertificateError(err):
+			return false, fmt.Sprintf("TLS certificate error, %v", err)
+		case isDialError(err):
+			// the request has not been sent
+			return true, fmt.Sprintf("could not connect, %v", err)
+		case errors.Is(err, syscall.ECONNRESET):
+			return idempotent, fmt.Sprintf("connection reset, %v", err)
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			return idempotent, fmt.Sprintf("connection closed, %v", err)
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return idempotent, fmt.Sprintf("timeout, %v", err)
+		}
+		return false, fmt.Sprintf("transport error, %v", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// the broker has rejected the request without processing it
+		return true, fmt.Sprintf("status %v", resp.Status)
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		// the broker behind a load balancer or proxy may have processed the request
+		return idempotent, fmt.Sprintf("status %v", resp.Status)
+	case http.StatusOK, http.StatusBadRequest:
+		return false, ""
+	}
+	return false, fmt.Sprintf("status %v", resp.Status)
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var verification *tls.CertificateVerificationError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid) || errors.As(err, &verification)
+}
+
+// retryBackoff waits as long as the broker asks for in the Retry-After header, but no longer than max, otherwise it
+// backs off exponentially
+func retryBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusBadGateway:
+			if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if wait > max {
+					return max
+				}
+				return wait
+			}
+		}
+	}
+	return retryablehttp.DefaultBackoff(min, max, attemptNum, nil)
+}
+
+// parseRetryAfter accepts the delay in seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	retryTime, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(retryTime.Sub(now), 0), true
+}
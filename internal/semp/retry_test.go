@@ -0,0 +1,152 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	// failures is the number of failed responses before the broker succeeds
+	failWith := func(status int, failures int64, retryAfter string) func(*testing.T, *atomic.Int64) http.HandlerFunc {
+		return func(t *testing.T, attempts *atomic.Int64) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) > failures {
+					aboutApiHandler(w, r)
+					return
+				}
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(status)
+			}
+		}
+	}
+	resetConnection := func(t *testing.T, attempts *atomic.Int64) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) > 1 {
+				aboutApiHandler(w, r)
+				return
+			}
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// discard unsent data so that closing the connection sends a TCP reset
+			tcpConn := conn.(*tls.Conn).NetConn().(*net.TCPConn)
+			_ = tcpConn.SetLinger(0)
+			_ = tcpConn.Close()
+		}
+	}
+	tests := []struct {
+		name         string
+		method       string
+		handler      func(*testing.T, *atomic.Int64) http.HandlerFunc
+		wantAttempts int64
+		wantErr      bool
+		// wantStatus is the HTTP status of the expected SEMP error, if any
+		wantStatus int
+	}{
+		{"GET retries 503", http.MethodGet, failWith(http.StatusServiceUnavailable, 1, ""), 2, false, 0},
+		{"GET retries 502", http.MethodGet, failWith(http.StatusBadGateway, 2, ""), 3, false, 0},
+		{"GET retries 429 with Retry-After", http.MethodGet, failWith(http.StatusTooManyRequests, 1, "0"), 2, false, 0},
+		{"GET retries connection reset", http.MethodGet, resetConnection, 2, false, 0},
+		{"GET does not retry 401", http.MethodGet, failWith(http.StatusUnauthorized, 1, ""), 1, true, http.StatusUnauthorized},
+		{"GET does not retry 403", http.MethodGet, failWith(http.StatusForbidden, 1, ""), 1, true, http.StatusForbidden},
+		{"GET does not retry 404", http.MethodGet, failWith(http.StatusNotFound, 1, ""), 1, true, http.StatusNotFound},
+		{"GET reports last status after retries", http.MethodGet, failWith(http.StatusServiceUnavailable, 5, ""), 3, true, http.StatusServiceUnavailable},
+		{"PUT retries 502", http.MethodPut, failWith(http.StatusBadGateway, 1, ""), 2, false, 0},
+		{"POST retries 429", http.MethodPost, failWith(http.StatusTooManyRequests, 1, ""), 2, false, 0},
+		{"POST retries 503", http.MethodPost, failWith(http.StatusServiceUnavailable, 1, ""), 2, false, 0},
+		{"POST does not retry 502", http.MethodPost, failWith(http.StatusBadGateway, 1, ""), 1, true, http.StatusBadGateway},
+		{"POST does not retry connection reset", http.MethodPost, resetConnection, 1, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int64
+			broker := newTestBroker(t, tt.handler(t, &attempts))
+			client := NewClient(broker.URL, true, false, BasicAuth("admin", "admin"), Retries(2, time.Millisecond, time.Millisecond))
+			var err error
+			if tt.method == http.MethodGet {
+				_, err = client.RequestWithoutBody(context.Background(), tt.method, "/about/api")
+			} else {
+				_, err = client.RequestWithBody(context.Background(), tt.method, "/about/api", map[string]any{})
+			}
+			if n := attempts.Load(); n != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, n)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			var sempErr *Error
+			if tt.wantStatus != 0 && (!errors.As(err, &sempErr) || sempErr.HTTPStatus != tt.wantStatus) {
+				t.Errorf("expected SEMP error with status %d, got %v", tt.wantStatus, err)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 01 Jan 2024 12:00:10 GMT", 10 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "retry after", statusCode: http.StatusServiceUnavailable, retryAfter: "5", want: 5 * time.Second},
+		{name: "retry after capped", statusCode: http.StatusTooManyRequests, retryAfter: "3600", want: 30 * time.Second},
+		{name: "exponential", statusCode: http.StatusGatewayTimeout, retryAfter: "5", want: 4 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.statusCode, Header: http.Header{"Retry-After": []string{tt.retryAfter}}}
+			if got := retryBackoff(time.Second, 30*time.Second, 2, resp); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,444 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package semptest provides an in-memory SEMP v2 config API broker for tests, built from the object types
+// registered in the provider. It supports creating, reading, updating, replacing and deleting objects, listing
+// collections with paging, and reports NOT_FOUND, ALREADY_EXISTS, INVALID_PATH and parameter errors like a broker.
+// Sensitive attributes are write-only: they are stored but never returned.
+package semptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"terraform-provider-solacebroker/internal/broker"
+	"terraform-provider-solacebroker/internal/semp"
+)
+
+// DefaultPageSize is the number of objects returned per page if the request does not specify a count
+const DefaultPageSize = 10
+
+// Broker is an in-memory SEMP v2 config API
+type Broker struct {
+	basePath    string
+	platform    string
+	sempVersion string
+	username    string
+	password    string
+	pageSize    int
+	entities    []*entity
+	root        *entity
+
+	mu      sync.Mutex
+	objects map[string]*object
+	created int
+}
+
+type object struct {
+	entity *entity
+	values map[string]any
+	// created orders the objects of a collection
+	created int
+}
+
+type Option func(*Broker)
+
+// BasicAuth makes the broker require basic authentication with the given credentials
+func BasicAuth(username, password string) Option {
+	return func(b *Broker) {
+		b.username = username
+		b.password = password
+	}
+}
+
+// PageSize sets the number of objects returned per page if the request does not specify a count
+func PageSize(pageSize int) Option {
+	return func(b *Broker) {
+		b.pageSize = pageSize
+	}
+}
+
+// Entities replaces the object types of the broker, by default those registered in the provider are used
+func Entities(entities []broker.EntityInputs) Option {
+	return func(b *Broker) {
+		b.entities = nil
+		for _, inputs := range entities {
+			b.entities = append(b.entities, newEntity(inputs))
+		}
+	}
+}
+
+// New returns a broker serving the SEMP version and default platform the provider has been generated for
+func New(options ...Option) *Broker {
+	b := &Broker{
+		basePath:    broker.SempDetail.BasePath,
+		platform:    broker.SempDetail.Platform,
+		sempVersion: broker.SempDetail.SempVersion,
+		pageSize:    DefaultPageSize,
+		objects:     map[string]*object{},
+	}
+	Entities(broker.PlatformEntities(b.platform))(b)
+	for _, o := range options {
+		o(b)
+	}
+	b.basePath = strings.TrimSuffix(b.basePath, "/")
+	linkParents(b.entities)
+	for _, e := range b.entities {
+		if len(e.segments) == 0 {
+			b.root = e
+		}
+	}
+	return b
+}
+
+// Object returns the stored attributes of an object by its SEMP path, including write-only attributes
+func (b *Broker) Object(path string) (map[string]any, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.objects[b.canonicalPath(path)]
+	if !ok {
+		return nil, false
+	}
+	values := map[string]any{}
+	for k, v := range o.values {
+		values[k] = v
+	}
+	return values, true
+}
+
+func (b *Broker) canonicalPath(path string) string {
+	segments := splitPath(path)
+	for _, e := range b.entities {
+		if identifiers, ok := e.matchObject(segments); ok {
+			return e.path(identifiers, false)
+		}
+	}
+	return path
+}
+
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if b.username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != b.username || password != b.password {
+			writeError(w, http.StatusUnauthorized, semp.StatusUnauthorized, "Authentication failed")
+			return
+		}
+	}
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, b.basePath) {
+		writeError(w, http.StatusNotFound, semp.StatusInvalidPath, fmt.Sprintf("Unknown path %v", path))
+		return
+	}
+	path = strings.TrimPrefix(path, b.basePath)
+	if path == "/about/api" && r.Method == http.MethodGet {
+		writeData(w, map[string]any{"platform": b.platform, "sempVersion": b.sempVersion}, nil)
+		return
+	}
+	segments := splitPath(path)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range b.entities {
+		if identifiers, ok := e.matchObject(segments); ok {
+			b.serveObject(w, r, e, identifiers)
+			return
+		}
+	}
+	for _, e := range b.entities {
+		if identifiers, ok := e.matchCollection(segments); ok {
+			b.serveCollection(w, r, e, identifiers)
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, semp.StatusInvalidPath, fmt.Sprintf("Invalid path %v", path))
+}
+
+func (b *Broker) serveObject(w http.ResponseWriter, r *http.Request, e *entity, identifiers map[string]string) {
+	path := e.path(identifiers, false)
+	o, exists := b.objects[path]
+	if !exists && e == b.root {
+		o = b.create(e, identifiers, nil)
+		exists = true
+	}
+	if !exists && r.Method != http.MethodPut {
+		writeError(w, http.StatusBadRequest, semp.StatusNotFound, fmt.Sprintf("Could not find match for %v", path))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeData(w, o.data(), nil)
+	case http.MethodPut, http.MethodPatch:
+		if e.ObjectType == broker.ReplaceOnlyObject {
+			writeError(w, http.StatusBadRequest, semp.StatusNotAllowed, fmt.Sprintf("Objects at %v cannot be changed, only replaced", path))
+			return
+		}
+		body, ok := b.readBody(w, r, e, identifiers)
+		if !ok {
+			return
+		}
+		if !exists {
+			if !b.parentExists(w, e, identifiers) {
+				return
+			}
+			o = b.create(e, identifiers, body)
+		} else if r.Method == http.MethodPut {
+			// write-only attributes are not updated when absent in a PUT
+			for name, value := range o.values {
+				if _, ok := body[name]; !ok && e.attributes[name] != nil && e.attributes[name].Sensitive {
+					body[name] = value
+				}
+			}
+			created := o.created
+			o = b.create(e, identifiers, body)
+			o.created = created
+		} else {
+			o.update(body)
+		}
+		writeData(w, o.data(), nil)
+	case http.MethodDelete:
+		if !e.isCollection() {
+			writeError(w, http.StatusBadRequest, semp.StatusNotAllowed, fmt.Sprintf("Object %v cannot be deleted", path))
+			return
+		}
+		for key := range b.objects {
+			if key == path || strings.HasPrefix(key, path+"/") {
+				delete(b.objects, key)
+			}
+		}
+		writeData(w, nil, nil)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, semp.StatusNotAllowed, fmt.Sprintf("Method %v is not allowed for %v", r.Method, path))
+	}
+}
+
+func (b *Broker) serveCollection(w http.ResponseWriter, r *http.Request, e *entity, parentIdentifiers map[string]string) {
+	collectionPath := e.path(parentIdentifiers, true)
+	switch r.Method {
+	case http.MethodGet:
+		if !b.parentExists(w, e, parentIdentifiers) {
+			return
+		}
+		b.list(w, r, e, collectionPath)
+	case http.MethodPost:
+		if e.PostPathTemplate == "" {
+			writeError(w, http.StatusBadRequest, semp.StatusNotAllowed, fmt.Sprintf("Objects cannot be created at %v", collectionPath))
+			return
+		}
+		body, ok := b.readBody(w, r, e, parentIdentifiers)
+		if !ok {
+			return
+		}
+		identifiers := map[string]string{}
+		for k, v := range parentIdentifiers {
+			identifiers[k] = v
+		}
+		for _, identifier := range e.ownIdentifiers() {
+			value, ok := body[identifier]
+			if !ok || value == nil {
+				writeError(w, http.StatusBadRequest, semp.StatusMissingParameter, fmt.Sprintf("Missing attribute %v", identifier))
+				return
+			}
+			identifiers[identifier] = fmt.Sprint(value)
+		}
+		if !b.parentExists(w, e, identifiers) {
+			return
+		}
+		path := e.path(identifiers, false)
+		if _, exists := b.objects[path]; exists {
+			writeError(w, http.StatusBadRequest, semp.StatusAlreadyExists, fmt.Sprintf("Object %v already exists", path))
+			return
+		}
+		writeData(w, b.create(e, identifiers, body).data(), nil)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, semp.StatusNotAllowed, fmt.Sprintf("Method %v is not allowed for %v", r.Method, collectionPath))
+	}
+}
+
+// list returns a page of the objects of a collection, the cursor is the index of the first object of the page
+func (b *Broker) list(w http.ResponseWriter, r *http.Request, e *entity, collectionPath string) {
+	var objects []*object
+	for key, o := range b.objects {
+		if o.entity == e && strings.HasPrefix(key, collectionPath+"/") {
+			objects = append(objects, o)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].created < objects[j].created })
+	query := r.URL.Query()
+	count := b.pageSize
+	if value := query.Get("count"); value != "" {
+		var err error
+		if count, err = strconv.Atoi(value); err != nil || count < 1 {
+			writeError(w, http.StatusBadRequest, semp.StatusInvalidParameter, fmt.Sprintf("Invalid count %v", value))
+			return
+		}
+	}
+	start := 0
+	if value := query.Get("cursor"); value != "" {
+		var err error
+		if start, err = strconv.Atoi(value); err != nil || start < 0 {
+			writeError(w, http.StatusBadRequest, semp.StatusInvalidParameter, fmt.Sprintf("Invalid cursor %v", value))
+			return
+		}
+	}
+	data := []map[string]any{}
+	for i := start; i < len(objects) && i < start+count; i++ {
+		data = append(data, objects[i].data())
+	}
+	var paging map[string]any
+	if next := start + count; next < len(objects) {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		cursor := strconv.Itoa(next)
+		paging = map[string]any{
+			"cursor":      cursor,
+			"nextPageUri": fmt.Sprintf("%v://%v%v%v?count=%d&cursor=%v", scheme, r.Host, b.basePath, collectionPath, count, cursor),
+		}
+	}
+	writeData(w, data, paging)
+}
+
+// readBody decodes the request body and validates its attributes against the object type
+func (b *Broker) readBody(w http.ResponseWriter, r *http.Request, e *entity, identifiers map[string]string) (map[string]any, bool) {
+	body := map[string]any{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, semp.StatusInvalidParameter, fmt.Sprintf("Invalid request body, %v", err))
+		return nil, false
+	}
+	for name, value := range body {
+		attr, ok := e.attributes[name]
+		switch {
+		case !ok:
+			writeError(w, http.StatusBadRequest, semp.StatusInvalidParameter, fmt.Sprintf("Unknown attribute %v", name))
+			return nil, false
+		case attr.ReadOnly && !attr.Identifying:
+			writeError(w, http.StatusBadRequest, semp.StatusInvalidParameter, fmt.Sprintf("Attribute %v is read-only", name))
+			return nil, false
+		case attr.Identifying && identifiers[name] != "" && fmt.Sprint(value) != identifiers[name]:
+			writeError(w, http.StatusBadRequest, semp.StatusInvalidParameter, fmt.Sprintf("Attribute %v does not match the path", name))
+			return nil, false
+		}
+	}
+	return body, true
+}
+
+func (b *Broker) parentExists(w http.ResponseWriter, e *entity, identifiers map[string]string) bool {
+	if e.parent == nil {
+		return true
+	}
+	path := e.parent.path(identifiers, false)
+	if _, exists := b.objects[path]; !exists {
+		writeError(w, http.StatusBadRequest, semp.StatusNotFound, fmt.Sprintf("Could not find match for %v", path))
+		return false
+	}
+	return true
+}
+
+// create stores a new object with the default attribute values, overridden by the given values
+func (b *Broker) create(e *entity, identifiers map[string]string, values map[string]any) *object {
+	b.created++
+	o := &object{entity: e, values: map[string]any{}, created: b.created}
+	for _, attr := range e.Attributes {
+		if value := defaultValue(attr); value != nil {
+			o.values[attr.SempName] = value
+		}
+	}
+	for name, value := range identifiers {
+		o.values[name] = value
+	}
+	o.update(values)
+	b.objects[e.path(identifiers, false)] = o
+	return o
+}
+
+func defaultValue(attr *broker.AttributeInfo) any {
+	if len(attr.Attributes) == 0 {
+		return attr.Default
+	}
+	values := map[string]any{}
+	for _, child := range attr.Attributes {
+		if value := defaultValue(child); value != nil {
+			values[child.SempName] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// update sets the given attribute values, a null value resets the attribute to its default
+func (o *object) update(values map[string]any) {
+	for name, value := range values {
+		if value == nil {
+			value = defaultValue(o.entity.attributes[name])
+		}
+		if value == nil {
+			delete(o.values, name)
+		} else {
+			o.values[name] = value
+		}
+	}
+}
+
+// data returns the attributes of the object as returned by a GET request, without write-only attributes
+func (o *object) data() map[string]any {
+	data := map[string]any{}
+	for name, value := range o.values {
+		if attr, ok := o.entity.attributes[name]; ok && attr.Sensitive {
+			continue
+		}
+		data[name] = value
+	}
+	return data
+}
+
+func writeData(w http.ResponseWriter, data any, paging map[string]any) {
+	meta := map[string]any{"responseCode": http.StatusOK}
+	if paging != nil {
+		meta["paging"] = paging
+	}
+	response := map[string]any{"meta": meta}
+	if data != nil {
+		response["data"] = data
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func writeError(w http.ResponseWriter, httpStatus int, status, description string) {
+	writeJSON(w, httpStatus, map[string]any{
+		"meta": map[string]any{
+			"responseCode": httpStatus,
+			"error": map[string]any{
+				"status":      status,
+				"description": description,
+			},
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, httpStatus int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	_ = json.NewEncoder(w).Encode(response)
+}
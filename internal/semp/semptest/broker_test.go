@@ -0,0 +1,182 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"terraform-provider-solacebroker/internal/broker"
+	_ "terraform-provider-solacebroker/internal/broker/generated"
+	"terraform-provider-solacebroker/internal/semp"
+)
+
+// newServer starts a broker that is stopped when the test ends, use the server URL as the provider url
+func newServer(t testing.TB, options ...Option) (*httptest.Server, *Broker) {
+	t.Helper()
+	b := New(options...)
+	server := httptest.NewServer(b)
+	t.Cleanup(server.Close)
+	return server, b
+}
+
+func newTestClient(t *testing.T, options ...Option) (*semp.Client, *Broker) {
+	t.Helper()
+	server, b := newServer(t, append([]Option{BasicAuth("admin", "admin")}, options...)...)
+	client := semp.NewClient(server.URL+broker.SempDetail.BasePath, false, false,
+		semp.BasicAuth("admin", "admin"),
+		semp.RequestLimits(0, 0),
+		semp.Retries(0, 0, 0))
+	return client, b
+}
+
+func TestBroker(t *testing.T) {
+	ctx := context.Background()
+	client, b := newTestClient(t)
+
+	about, err := client.RequestWithoutBody(ctx, http.MethodGet, "/about/api")
+	if err != nil || about["platform"] != broker.SempDetail.Platform || about["sempVersion"] != broker.SempDetail.SempVersion {
+		t.Fatalf("GET /about/api = %v, %v", about, err)
+	}
+
+	vpn, err := client.RequestWithBody(ctx, http.MethodPut, "/msgVpns/test", map[string]any{"msgVpnName": "test", "enabled": true})
+	if err != nil {
+		t.Fatalf("PUT msgVpn: %v", err)
+	}
+	if vpn["enabled"] != true || vpn["authenticationBasicEnabled"] != true {
+		t.Errorf("PUT msgVpn returned %v, want set and default values", vpn)
+	}
+
+	_, err = client.RequestWithBody(ctx, http.MethodPut, "/msgVpns/test/clientUsernames/a%2Fb", map[string]any{"clientUsername": "a/b", "password": "secret"})
+	if err != nil {
+		t.Fatalf("PUT clientUsername: %v", err)
+	}
+	user, err := client.RequestWithoutBody(ctx, http.MethodGet, "/msgVpns/test/clientUsernames/a%2Fb")
+	if err != nil {
+		t.Fatalf("GET clientUsername: %v", err)
+	}
+	if _, ok := user["password"]; ok || user["clientUsername"] != "a/b" {
+		t.Errorf("GET clientUsername = %v, want identifier without write-only password", user)
+	}
+	if _, err := client.RequestWithBody(ctx, http.MethodPut, "/msgVpns/test/clientUsernames/a%2Fb", map[string]any{"enabled": true}); err != nil {
+		t.Fatalf("PUT clientUsername: %v", err)
+	}
+	if stored, _ := b.Object("/msgVpns/test/clientUsernames/a%2Fb"); stored["password"] != "secret" || stored["enabled"] != true {
+		t.Errorf("stored clientUsername = %v, want password kept", stored)
+	}
+
+	// queue subscriptions are created with POST
+	if _, err := client.RequestWithBody(ctx, http.MethodPut, "/msgVpns/test/queues/q", map[string]any{}); err != nil {
+		t.Fatalf("PUT queue: %v", err)
+	}
+	subscription := map[string]any{"subscriptionTopic": "a/>"}
+	if _, err := client.RequestWithBody(ctx, http.MethodPost, "/msgVpns/test/queues/q/subscriptions", subscription); err != nil {
+		t.Fatalf("POST queue subscription: %v", err)
+	}
+	if _, err := client.RequestWithBody(ctx, http.MethodPost, "/msgVpns/test/queues/q/subscriptions", subscription); !isStatus(err, semp.StatusAlreadyExists) {
+		t.Errorf("POST existing queue subscription error = %v, want ALREADY_EXISTS", err)
+	}
+	if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/msgVpns/test/queues/q/subscriptions/a%2F%3E"); err != nil {
+		t.Errorf("GET queue subscription: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   map[string]any
+		status string
+	}{
+		{"MissingObject", http.MethodGet, "/msgVpns/other", nil, semp.StatusNotFound},
+		{"MissingParent", http.MethodPut, "/msgVpns/other/queues/q", map[string]any{}, semp.StatusNotFound},
+		{"PatchMissingObject", http.MethodPatch, "/msgVpns/test/queues/other", map[string]any{}, semp.StatusNotFound},
+		{"UnknownPath", http.MethodGet, "/msgVpns/test/unknown", nil, semp.StatusInvalidPath},
+		{"UnknownAttribute", http.MethodPatch, "/msgVpns/test", map[string]any{"unknown": 1}, semp.StatusInvalidParameter},
+		{"IdentifierMismatch", http.MethodPatch, "/msgVpns/test", map[string]any{"msgVpnName": "other"}, semp.StatusInvalidParameter},
+		{"MissingIdentifier", http.MethodPost, "/msgVpns/test/queues/q/subscriptions", map[string]any{}, semp.StatusMissingParameter},
+		{"PostNotAllowed", http.MethodPost, "/msgVpns/test/queues", map[string]any{"queueName": "other"}, semp.StatusNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.body == nil {
+				_, err = client.RequestWithoutBody(ctx, tt.method, tt.path)
+			} else {
+				_, err = client.RequestWithBody(ctx, tt.method, tt.path, tt.body)
+			}
+			if !isStatus(err, tt.status) {
+				t.Errorf("%v %v error = %v, want %v", tt.method, tt.path, err, tt.status)
+			}
+		})
+	}
+
+	if _, err := client.RequestWithoutBody(ctx, http.MethodDelete, "/msgVpns/test"); err != nil {
+		t.Fatalf("DELETE msgVpn: %v", err)
+	}
+	if _, ok := b.Object("/msgVpns/test/clientUsernames/a%2Fb"); ok {
+		t.Errorf("clientUsername not deleted with its msgVpn")
+	}
+	if _, err := client.RequestWithoutBody(ctx, http.MethodDelete, "/msgVpns/test"); !errors.Is(err, semp.ErrResourceNotFound) {
+		t.Errorf("DELETE deleted msgVpn error = %v, want not found", err)
+	}
+}
+
+func TestBrokerPaging(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t, PageSize(3))
+	if _, err := client.RequestWithBody(ctx, http.MethodPut, "/msgVpns/test", map[string]any{"msgVpnName": "test"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		if _, err := client.RequestWithBody(ctx, http.MethodPut, fmt.Sprintf("/msgVpns/test/queues/q%d", i), map[string]any{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queues, err := client.RequestWithoutBodyForGenerator(ctx, strings.TrimPrefix(broker.SempDetail.BasePath, "/"), http.MethodGet, "/msgVpns/test/queues", []map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queues) != 7 {
+		t.Fatalf("got %d queues, want 7", len(queues))
+	}
+	for i, q := range queues {
+		if q["queueName"] != fmt.Sprintf("q%d", i) {
+			t.Errorf("queue %d = %v, want creation order", i, q["queueName"])
+		}
+	}
+}
+
+func TestBrokerAuthentication(t *testing.T) {
+	server, _ := newServer(t, BasicAuth("admin", "admin"))
+	client := semp.NewClient(server.URL+broker.SempDetail.BasePath, false, false,
+		semp.BasicAuth("admin", "wrong"),
+		semp.Retries(0, 0, 0))
+	_, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api")
+	if !isStatus(err, semp.StatusUnauthorized) {
+		t.Errorf("error = %v, want UNAUTHORIZED", err)
+	}
+}
+
+func isStatus(err error, status string) bool {
+	var sempErr *semp.Error
+	return errors.As(err, &sempErr) && sempErr.Status == status
+}
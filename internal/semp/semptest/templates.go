@@ -0,0 +1,159 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semptest
+
+import (
+	"net/url"
+	"strings"
+
+	"terraform-provider-solacebroker/internal/broker"
+)
+
+// segment is one segment of a path template, either a literal or one or more comma-separated identifiers
+type segment struct {
+	literal     string
+	identifiers []string
+}
+
+// entity is a broker object type with its parsed path template
+type entity struct {
+	broker.EntityInputs
+	segments   []segment
+	attributes map[string]*broker.AttributeInfo
+	// parent is the object type containing objects of this type, nil for top-level objects and unknown parents
+	parent *entity
+}
+
+func newEntity(inputs broker.EntityInputs) *entity {
+	e := &entity{EntityInputs: inputs, attributes: map[string]*broker.AttributeInfo{}}
+	for _, s := range splitPath(inputs.PathTemplate) {
+		if strings.HasPrefix(s, "{") {
+			var identifiers []string
+			for _, identifier := range strings.Split(s, ",") {
+				identifiers = append(identifiers, strings.Trim(identifier, "{}"))
+			}
+			e.segments = append(e.segments, segment{identifiers: identifiers})
+		} else {
+			e.segments = append(e.segments, segment{literal: s})
+		}
+	}
+	for _, attr := range inputs.Attributes {
+		e.attributes[attr.SempName] = attr
+	}
+	return e
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// isCollection reports whether objects of this type are addressed by identifiers within a collection
+func (e *entity) isCollection() bool {
+	return len(e.segments) > 0 && e.segments[len(e.segments)-1].identifiers != nil
+}
+
+// matchObject returns the identifiers of the object if the escaped path segments address an object of this type
+func (e *entity) matchObject(segments []string) (map[string]string, bool) {
+	if len(segments) != len(e.segments) {
+		return nil, false
+	}
+	return e.match(segments)
+}
+
+// matchCollection returns the identifiers of the parent if the escaped path segments address the collection of
+// objects of this type
+func (e *entity) matchCollection(segments []string) (map[string]string, bool) {
+	if !e.isCollection() || len(segments) != len(e.segments)-1 {
+		return nil, false
+	}
+	return e.match(segments)
+}
+
+func (e *entity) match(segments []string) (map[string]string, bool) {
+	identifiers := map[string]string{}
+	for i, s := range segments {
+		t := e.segments[i]
+		if t.identifiers == nil {
+			if s != t.literal {
+				return nil, false
+			}
+			continue
+		}
+		values := strings.Split(s, ",")
+		if len(values) != len(t.identifiers) {
+			return nil, false
+		}
+		for j, value := range values {
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, false
+			}
+			identifiers[t.identifiers[j]] = unescaped
+		}
+	}
+	return identifiers, true
+}
+
+// path returns the canonical path of an object of this type, or of its collection if collection is set
+func (e *entity) path(identifiers map[string]string, collection bool) string {
+	segments := e.segments
+	if collection {
+		segments = segments[:len(segments)-1]
+	}
+	var path strings.Builder
+	for _, s := range segments {
+		path.WriteString("/")
+		if s.identifiers == nil {
+			path.WriteString(s.literal)
+			continue
+		}
+		for i, identifier := range s.identifiers {
+			if i > 0 {
+				path.WriteString(",")
+			}
+			path.WriteString(url.PathEscape(identifiers[identifier]))
+		}
+	}
+	return path.String()
+}
+
+// ownIdentifiers returns the identifiers of an object that are not identifiers of its parent
+func (e *entity) ownIdentifiers() []string {
+	if !e.isCollection() {
+		return nil
+	}
+	return e.segments[len(e.segments)-1].identifiers
+}
+
+// linkParents sets the parent of each entity whose path template extends that of another entity by a collection
+func linkParents(entities []*entity) {
+	byPath := map[string]*entity{}
+	for _, e := range entities {
+		byPath[e.PathTemplate] = e
+	}
+	for _, e := range entities {
+		if len(e.segments) < 3 {
+			continue
+		}
+		parentPath := "/" + strings.Join(splitPath(e.PathTemplate)[:len(e.segments)-2], "/")
+		e.parent = byPath[parentPath]
+	}
+}
@@ -0,0 +1,186 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// sessionLoginPath is requested with basic authentication to obtain a session cookie, relative to the config API
+	sessionLoginPath = "/about/user"
+	// sessionLogoutPath ends the session, relative to the action API. It is the "logout" action of the about/user
+	// object in the SEMP v2 action API reference (operation doAboutUserLogout, PUT /about/user/logout), available
+	// since SEMP API version 2.24.
+	sessionLogoutPath = "/about/user/logout"
+)
+
+// SessionLogin makes the client log in once with the basic authentication credentials and authenticate all later
+// requests with the session cookie of the broker. The session is renewed when it expires. If the broker does not
+// return a session cookie, basic authentication is used for every request.
+func SessionLogin() Option {
+	return func(client *Client) {
+		client.session = &session{cookies: map[string]string{}}
+	}
+}
+
+type session struct {
+	jar *sessionJar
+
+	mu sync.Mutex
+	// cookies holds the session cookies of each node logged in to, by origin
+	cookies map[string]string
+	// unsupported is set if the broker did not return a session cookie
+	unsupported bool
+}
+
+// sessionJar is a cookie jar that can be emptied while in use
+type sessionJar struct {
+	mu  sync.RWMutex
+	jar *cookiejar.Jar
+}
+
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil)
+	return &sessionJar{jar: jar}
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
+}
+
+func (j *sessionJar) reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar, _ = cookiejar.New(nil)
+}
+
+// sessionCookie returns the session cookies for the node of the request, logging in first if there is no session. It
+// returns an empty string if the broker does not support sessions.
+func (c *Client) sessionCookie(ctx context.Context, u *url.URL) (string, error) {
+	s := c.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unsupported {
+		return "", nil
+	}
+	origin := u.Scheme + "://" + u.Host
+	if cookie, ok := s.cookies[origin]; ok {
+		return cookie, nil
+	}
+	loginURL := c.nodeURL(origin) + sessionLoginPath
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, loginURL, nil)
+	if err != nil {
+		return "", err
+	}
+	request.SetBasicAuth(c.username, c.password)
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("could not log in to %v: %w", origin, err)
+	}
+	defer response.Body.Close()
+	rawBody, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK {
+		return "", newError(request, response.StatusCode, nil, rawBody)
+	}
+	var cookies []string
+	for _, cookie := range s.jar.Cookies(request.URL) {
+		cookies = append(cookies, cookie.String())
+	}
+	if len(cookies) == 0 {
+		tflog.Warn(ctx, fmt.Sprintf("Broker %v did not return a session cookie, using basic authentication for every request", origin))
+		s.unsupported = true
+		return "", nil
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Logged in to %v", origin))
+	s.cookies[origin] = strings.Join(cookies, "; ")
+	return s.cookies[origin], nil
+}
+
+// invalidateSession drops the sessions if cookie is still current, so that the next request logs in again
+func (c *Client) invalidateSession(cookie string) {
+	s := c.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, current := range s.cookies {
+		if current == cookie {
+			s.cookies = map[string]string{}
+			s.jar.reset()
+			return
+		}
+	}
+}
+
+// nodeURL returns the SEMP URL of the node with the given origin
+func (c *Client) nodeURL(origin string) string {
+	for _, url := range c.urls {
+		if strings.HasPrefix(url, origin+"/") {
+			return url
+		}
+	}
+	return c.urls[0]
+}
+
+// Logout ends the broker sessions of the client, if any
+func (c *Client) Logout(ctx context.Context) {
+	if c.session == nil {
+		return
+	}
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	c.logout(ctx)
+}
+
+// logout ends the broker sessions of the client, the session lock must be held
+func (c *Client) logout(ctx context.Context) {
+	s := c.session
+	for origin := range s.cookies {
+		logoutURL := strings.TrimSuffix(c.nodeURL(origin), "/config") + "/action" + sessionLogoutPath
+		request, err := http.NewRequestWithContext(ctx, http.MethodPut, logoutURL, strings.NewReader("{}"))
+		if err != nil {
+			continue
+		}
+		request.Header.Set("Content-Type", "application/json")
+		response, err := c.HTTPClient.Do(request)
+		if err != nil {
+			tflog.Debug(ctx, fmt.Sprintf("Could not log out from %v, %v", origin, err))
+			continue
+		}
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+		tflog.Debug(ctx, fmt.Sprintf("Logged out from %v, status %v", origin, response.Status))
+	}
+	s.cookies = map[string]string{}
+	s.jar.reset()
+}
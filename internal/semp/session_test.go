@@ -0,0 +1,123 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// sessionBroker issues a session cookie for requests with valid basic authentication and accepts it until expired
+type sessionBroker struct {
+	issueCookies bool
+
+	mu         sync.Mutex
+	basicAuths int
+	sessions   map[string]bool
+	logouts    int
+}
+
+func (b *sessionBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.Method == http.MethodPut && r.URL.Path == "/SEMP/v2/action/about/user/logout" {
+		if cookie, err := r.Cookie("Session"); err == nil {
+			delete(b.sessions, cookie.Value)
+			b.logouts++
+		}
+		return
+	}
+	if cookie, err := r.Cookie("Session"); err == nil && b.sessions[cookie.Value] {
+		aboutApiHandler(w, r)
+		return
+	}
+	if username, password, ok := r.BasicAuth(); ok && username == "admin" && password == "admin" {
+		b.basicAuths++
+		if b.issueCookies {
+			id := fmt.Sprintf("session%d", b.basicAuths)
+			b.sessions[id] = true
+			http.SetCookie(w, &http.Cookie{Name: "Session", Value: id, Path: "/"})
+		}
+		aboutApiHandler(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+func (b *sessionBroker) expireSessions() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sessions = map[string]bool{}
+}
+
+func TestSessionLogin(t *testing.T) {
+	tests := []struct {
+		name           string
+		issueCookies   bool
+		wantBasicAuths int
+		wantLogouts    int
+	}{
+		// one login, one more after the session expired
+		{"SessionCookie", true, 2, 1},
+		// basic authentication for the login and every request
+		{"NoSessionCookie", false, 1 + 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &sessionBroker{issueCookies: tt.issueCookies, sessions: map[string]bool{}}
+			server := newTestBroker(t, broker.ServeHTTP)
+			client := NewClient(server.URL+"/SEMP/v2/config", true, false,
+				BasicAuth("admin", "admin"),
+				SessionLogin(),
+				Retries(0, 0, 0))
+			ctx := context.Background()
+			for i := 0; i < 10; i++ {
+				if i == 5 {
+					broker.expireSessions()
+				}
+				if _, err := client.RequestWithoutBody(ctx, http.MethodGet, "/about/api"); err != nil {
+					t.Fatalf("request %d failed, %v", i, err)
+				}
+			}
+			client.Logout(ctx)
+			if broker.basicAuths != tt.wantBasicAuths {
+				t.Errorf("got %d basic authentications, want %d", broker.basicAuths, tt.wantBasicAuths)
+			}
+			if broker.logouts != tt.wantLogouts {
+				t.Errorf("got %d logouts, want %d", broker.logouts, tt.wantLogouts)
+			}
+		})
+	}
+}
+
+func TestSessionLoginFailure(t *testing.T) {
+	broker := &sessionBroker{issueCookies: true, sessions: map[string]bool{}}
+	server := newTestBroker(t, broker.ServeHTTP)
+	client := NewClient(server.URL+"/SEMP/v2/config", true, false,
+		BasicAuth("admin", "wrong"),
+		SessionLogin(),
+		Retries(0, 0, 0))
+	_, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api")
+	var sempErr *Error
+	if !errors.As(err, &sempErr) || sempErr.HTTPStatus != http.StatusUnauthorized {
+		t.Errorf("got error %v, want unauthorized", err)
+	}
+}
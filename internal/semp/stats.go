@@ -0,0 +1,204 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// StatsFileEnv is the environment variable naming a file the SEMP request statistics are written to as JSON
+const StatsFileEnv = "SOLACEBROKER_SEMP_STATS_FILE"
+
+// EndpointStats are the statistics of the requests with the same method and path template. Durations are in
+// milliseconds, the percentiles are approximated to within 10% of the response time.
+type EndpointStats struct {
+	// Broker identifies the provider configuration that sent the requests, it is empty for the generator
+	Broker       string  `json:"broker,omitempty"`
+	Method       string  `json:"method"`
+	PathTemplate string  `json:"path_template"`
+	Count        int     `json:"count"`
+	Errors       int     `json:"errors"`
+	Retries      int     `json:"retries"`
+	ThrottleWait float64 `json:"throttle_wait_ms"`
+	Total        float64 `json:"total_ms"`
+	P50          float64 `json:"p50_ms"`
+	P90          float64 `json:"p90_ms"`
+	P99          float64 `json:"p99_ms"`
+	Max          float64 `json:"max_ms"`
+}
+
+type endpointKey struct {
+	method       string
+	pathTemplate string
+}
+
+// latencyBounds are the upper bounds of the latency histogram buckets, growing by 10% from 100µs to several minutes.
+// Latencies above the last bound are counted in an additional bucket.
+var latencyBounds = func() []time.Duration {
+	bounds := make([]time.Duration, 160)
+	bound := float64(100 * time.Microsecond)
+	for i := range bounds {
+		bounds[i] = time.Duration(bound)
+		bound *= 1.1
+	}
+	return bounds
+}()
+
+// latencyHistogram counts latencies in fixed buckets, so that its size does not grow with the number of requests
+type latencyHistogram struct {
+	buckets [161]int
+	count   int
+	total   time.Duration
+	max     time.Duration
+}
+
+func (h *latencyHistogram) add(latency time.Duration) {
+	i, _ := slices.BinarySearch(latencyBounds, latency)
+	h.buckets[i]++
+	h.count++
+	h.total += latency
+	h.max = max(h.max, latency)
+}
+
+// percentile returns the upper bound of the bucket holding the nearest-rank percentile, at most the maximum latency
+func (h *latencyHistogram) percentile(p int) time.Duration {
+	rank := max((p*h.count+99)/100, 1)
+	seen := 0
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= rank && i < len(latencyBounds) {
+			return min(latencyBounds[i], h.max)
+		}
+	}
+	return h.max
+}
+
+// endpointSamples are the outcomes of the requests to an endpoint, latencies exclude the rate limiter wait
+type endpointSamples struct {
+	latencies    latencyHistogram
+	errors       int
+	retries      int
+	throttleWait time.Duration
+}
+
+// requestStats collects the outcome of every request by endpoint
+type requestStats struct {
+	mu        sync.Mutex
+	endpoints map[endpointKey]*endpointSamples
+}
+
+func (s *requestStats) record(method, pathTemplate string, latency time.Duration, retries int, throttleWait time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := endpointKey{method: method, pathTemplate: pathTemplate}
+	if s.endpoints == nil {
+		s.endpoints = map[endpointKey]*endpointSamples{}
+	}
+	samples, ok := s.endpoints[key]
+	if !ok {
+		samples = &endpointSamples{}
+		s.endpoints[key] = samples
+	}
+	samples.latencies.add(latency)
+	samples.retries += retries
+	samples.throttleWait += throttleWait
+	if failed {
+		samples.errors++
+	}
+}
+
+// Stats returns the statistics of the requests sent by the client, the endpoints taking the most time first
+func (c *Client) Stats() []EndpointStats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	var stats []EndpointStats
+	for key, samples := range c.stats.endpoints {
+		latencies := &samples.latencies
+		stats = append(stats, EndpointStats{
+			Method:       key.method,
+			PathTemplate: key.pathTemplate,
+			Count:        latencies.count,
+			Errors:       samples.errors,
+			Retries:      samples.retries,
+			ThrottleWait: milliseconds(samples.throttleWait),
+			Total:        milliseconds(latencies.total),
+			P50:          milliseconds(latencies.percentile(50)),
+			P90:          milliseconds(latencies.percentile(90)),
+			P99:          milliseconds(latencies.percentile(99)),
+			Max:          milliseconds(latencies.max),
+		})
+	}
+	slices.SortFunc(stats, func(a, b EndpointStats) int {
+		if a.Total != b.Total {
+			if a.Total > b.Total {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Method+a.PathTemplate, b.Method+b.PathTemplate)
+	})
+	return stats
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// FormatStats formats request statistics as a table
+func FormatStats(stats []EndpointStats) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Method\tPath template\tCount\tErrors\tRetries\tThrottle wait\tTotal\tp50\tp90\tp99\tMax")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%v\t%v\t%d\t%d\t%d\t%v\t%v\t%v\t%v\t%v\t%v\n", s.Method, s.PathTemplate, s.Count, s.Errors, s.Retries,
+			formatMilliseconds(s.ThrottleWait), formatMilliseconds(s.Total), formatMilliseconds(s.P50), formatMilliseconds(s.P90),
+			formatMilliseconds(s.P99), formatMilliseconds(s.Max))
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+func formatMilliseconds(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
+
+// WriteStatsFile writes request statistics as JSON to the file named by SOLACEBROKER_SEMP_STATS_FILE, if set. The
+// file is replaced, so that it only holds the statistics of the current run.
+func WriteStatsFile(stats []EndpointStats) error {
+	path := os.Getenv(StatsFileEnv)
+	if path == "" {
+		return nil
+	}
+	if stats == nil {
+		stats = []EndpointStats{}
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("could not write SEMP request statistics: %w", err)
+	}
+	return nil
+}
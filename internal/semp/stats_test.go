@@ -0,0 +1,120 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/SEMP/v2/config/msgVpns/default" && attempts.Add(1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/SEMP/v2/config/msgVpns/"):
+			_, _ = w.Write([]byte(`{"data":{},"meta":{"responseCode":200}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"meta":{"responseCode":400,"error":{"code":11,"description":"Invalid path","status":"INVALID_PATH"}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(server.URL+"/SEMP/v2/config", false, false,
+		BasicAuth("admin", "admin"),
+		Retries(1, time.Millisecond, time.Millisecond),
+		PathTemplates("/msgVpns/{msgVpnName}"))
+	for _, path := range []string{"/msgVpns/default", "/msgVpns/test", "/msgVpns/other", "/unknown"} {
+		_, _ = client.RequestWithoutBody(context.Background(), http.MethodGet, path)
+	}
+
+	stats := client.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("got stats for %d endpoints, want 2: %+v", len(stats), stats)
+	}
+	byTemplate := map[string]EndpointStats{}
+	for _, s := range stats {
+		byTemplate[s.PathTemplate] = s
+	}
+	vpn := byTemplate["/msgVpns/{msgVpnName}"]
+	if vpn.Method != http.MethodGet || vpn.Count != 3 || vpn.Retries != 1 || vpn.Errors != 0 {
+		t.Errorf("got %+v, want 3 GET requests with 1 retry and no errors", vpn)
+	}
+	if vpn.P50 > vpn.P90 || vpn.P90 > vpn.Max || vpn.Max > vpn.Total {
+		t.Errorf("got inconsistent latencies %+v", vpn)
+	}
+	if unknown := byTemplate["/unknown"]; unknown.Count != 1 || unknown.Errors != 1 {
+		t.Errorf("got %+v, want 1 failed request", unknown)
+	}
+	if table := FormatStats(stats); !strings.Contains(table, "/msgVpns/{msgVpnName}") {
+		t.Errorf("got table without the path template:\n%v", table)
+	}
+
+	path := filepath.Join(t.TempDir(), "stats.json")
+	t.Setenv(StatsFileEnv, path)
+	if err := WriteStatsFile(stats); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written []EndpointStats
+	if err := json.Unmarshal(content, &written); err != nil || len(written) != 2 || written[0] != stats[0] {
+		t.Errorf("got %s, %v, want the statistics as JSON", content, err)
+	}
+}
+
+func TestLatencyHistogramPercentile(t *testing.T) {
+	var h latencyHistogram
+	for i := 1; i <= 100; i++ {
+		h.add(time.Duration(i) * time.Millisecond)
+	}
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		// the percentile is the upper bound of its bucket, within 10% above the exact value
+		if got := h.percentile(tt.p); got < tt.want || got > tt.want+tt.want/10 {
+			t.Errorf("percentile(%d) = %v, want %v within 10%%", tt.p, got, tt.want)
+		}
+	}
+	if h.count != 100 || h.max != 100*time.Millisecond || h.total != 5050*time.Millisecond {
+		t.Errorf("got count %d, max %v, total %v", h.count, h.max, h.total)
+	}
+
+	var single latencyHistogram
+	single.add(time.Hour)
+	if got := single.percentile(50); got != time.Hour {
+		t.Errorf("percentile(50) of a latency above the last bucket = %v, want %v", got, time.Hour)
+	}
+}
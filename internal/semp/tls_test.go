@@ -0,0 +1,61 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadClientCertificate(t *testing.T) {
+	certificateContent, err := os.ReadFile("testdata/client.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyContent, err := os.ReadFile("testdata/client.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		certificate string
+		privateKey  string
+		password    string
+		wantErr     bool
+	}{
+		{"FilePaths", "testdata/client.crt", "testdata/client.key", "", false},
+		{"PEMContent", string(certificateContent), string(privateKeyContent), "", false},
+		{"EncryptedPKCS8", "testdata/client.crt", "testdata/client-encrypted.key", "testpass", false},
+		{"EncryptedLegacy", "testdata/client.crt", "testdata/client-legacy-encrypted.key", "testpass", false},
+		{"EncryptedPKCS8WrongPassword", "testdata/client.crt", "testdata/client-encrypted.key", "wrong", true},
+		{"EncryptedLegacyWrongPassword", "testdata/client.crt", "testdata/client-legacy-encrypted.key", "wrong", true},
+		{"EncryptedNoPassword", "testdata/client.crt", "testdata/client-encrypted.key", "", true},
+		{"MissingFile", "testdata/missing.crt", "testdata/client.key", "", true},
+		{"KeyAsCertificate", "testdata/client.key", "testdata/client.key", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certificate, err := LoadClientCertificate(tt.certificate, tt.privateKey, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadClientCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(certificate.Certificate) == 0 {
+				t.Errorf("LoadClientCertificate() returned no certificate")
+			}
+		})
+	}
+}
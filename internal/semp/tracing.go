@@ -0,0 +1,165 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader carries a unique ID of each SEMP request, it is the same for all retries of a request
+	RequestIDHeader = "X-Request-ID"
+	// TraceFileEnv is the environment variable naming a file spans are appended to as JSON, one span per line
+	TraceFileEnv = "SOLACEBROKER_TRACE_FILE"
+	// TracerName is the name of the tracer of the provider and the generator
+	TracerName = "terraform-provider-solacebroker"
+)
+
+// Span attributes of SEMP requests, in addition to the OpenTelemetry HTTP client attributes
+const (
+	sempStatusKey     = attribute.Key("semp.error.status")
+	sempRetryCountKey = attribute.Key("semp.retry_count")
+	sempRateLimitKey  = attribute.Key("semp.rate_limit_wait_ms")
+	sempRequestIDKey  = attribute.Key("semp.request_id")
+)
+
+// StartTracing exports spans over OTLP/HTTP if an OTLP endpoint is set with the standard OTEL_EXPORTER_OTLP_ENDPOINT
+// or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables, and to the file named by SOLACEBROKER_TRACE_FILE. If
+// neither is set, spans are not recorded. The returned function flushes and stops the exporters.
+func StartTracing(ctx context.Context, serviceName, version string) (func(context.Context), error) {
+	var options []sdktrace.TracerProviderOption
+	var closers []func() error
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not create OTLP trace exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	if path := os.Getenv(TraceFileEnv); path != "" {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("could not open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("could not create trace file exporter: %w", err)
+		}
+		// spans are written as they end so that none are lost if the process is stopped
+		options = append(options, sdktrace.WithSyncer(exporter))
+		closers = append(closers, file.Close)
+	}
+	if len(options) == 0 {
+		return func(context.Context) {}, nil
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK())
+	if err != nil {
+		return nil, fmt.Errorf("could not create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(append(options, sdktrace.WithResource(res))...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return func(ctx context.Context) {
+		_ = provider.Shutdown(ctx)
+		for _, c := range closers {
+			_ = c()
+		}
+	}, nil
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// startRequestSpan starts the span of a SEMP request to the path template and sets the request ID and trace context
+// headers, the request returned carries the span in its context
+func (c *Client) startRequestSpan(request *http.Request, template string) (*http.Request, trace.Span) {
+	requestID := newRequestID()
+	ctx, span := otel.Tracer(TracerName).Start(request.Context(), "SEMP "+request.Method+" "+template,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", request.Method),
+			attribute.String("url.template", template),
+			attribute.String("server.address", request.URL.Hostname()),
+			sempRequestIDKey.String(requestID)))
+	request = request.WithContext(ctx)
+	request.Header.Set(RequestIDHeader, requestID)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+	return request, span
+}
+
+// endRequestSpan records the outcome of a SEMP request and ends its span
+func endRequestSpan(span trace.Span, statusCode int, sempStatus string, retries int, rateLimitWait time.Duration, err error) {
+	span.SetAttributes(sempRetryCountKey.Int(retries), sempRateLimitKey.Int64(rateLimitWait.Milliseconds()))
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	}
+	if sempStatus != "" {
+		span.SetAttributes(sempStatusKey.String(sempStatus))
+	}
+	var sempErr *Error
+	switch {
+	case errors.As(err, &sempErr):
+		span.SetStatus(codes.Error, sempErr.Status)
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case sempStatus != "":
+		span.SetStatus(codes.Error, sempStatus)
+	}
+	span.End()
+}
+
+// relativePath returns the escaped path of a SEMP URL without the base path, such as /SEMP/v2/config
+func (c *Client) relativePath(u *url.URL) string {
+	path := u.EscapedPath()
+	for _, nodeURL := range c.urls {
+		if base, err := url.Parse(nodeURL); err == nil && strings.HasPrefix(path, base.EscapedPath()) {
+			return strings.TrimPrefix(path, base.EscapedPath())
+		}
+	}
+	return path
+}
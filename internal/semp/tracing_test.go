@@ -0,0 +1,216 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var mu sync.Mutex
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requestIDs = append(requestIDs, r.Header.Get(RequestIDHeader))
+		attempt := len(requestIDs)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/SEMP/v2/config/msgVpns/default/queues/q1" && attempt == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/SEMP/v2/config/msgVpns/default/queues/q1":
+			_, _ = w.Write([]byte(`{"data":{"queueName":"q1"},"meta":{"responseCode":200}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"meta":{"responseCode":400,"error":{"code":6,"description":"Could not find match","status":"NOT_FOUND"}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL+"/SEMP/v2/config", false, false,
+		BasicAuth("admin", "admin"),
+		Retries(1, time.Millisecond, time.Millisecond),
+		PathTemplates("/msgVpns/{msgVpnName}", "/msgVpns/{msgVpnName}/queues/{queueName}"))
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/msgVpns/default/queues/q1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/msgVpns/default/queues?count=10"); err == nil {
+		t.Fatal("expected NOT_FOUND error")
+	}
+
+	if len(requestIDs) != 3 || requestIDs[0] == "" || requestIDs[0] != requestIDs[1] || requestIDs[1] == requestIDs[2] {
+		t.Errorf("got request IDs %q, want the same ID for a retry and a new ID for the next request", requestIDs)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	tests := []struct {
+		name       string
+		template   string
+		status     int64
+		sempStatus string
+		retries    int64
+		requestID  string
+	}{
+		{"SEMP GET /msgVpns/{msgVpnName}/queues/{queueName}", "/msgVpns/{msgVpnName}/queues/{queueName}", 200, "", 1, requestIDs[0]},
+		{"SEMP GET /msgVpns/{msgVpnName}/queues", "/msgVpns/{msgVpnName}/queues", 400, "NOT_FOUND", 0, requestIDs[2]},
+	}
+	for i, tt := range tests {
+		span := spans[i]
+		attributes := map[attribute.Key]attribute.Value{}
+		for _, a := range span.Attributes() {
+			attributes[a.Key] = a.Value
+		}
+		if span.Name() != tt.name {
+			t.Errorf("got span %q, want %q", span.Name(), tt.name)
+		}
+		if got := attributes["url.template"].AsString(); got != tt.template {
+			t.Errorf("%v: got url.template %q, want %q", tt.name, got, tt.template)
+		}
+		if got := attributes["http.response.status_code"].AsInt64(); got != tt.status {
+			t.Errorf("%v: got status %d, want %d", tt.name, got, tt.status)
+		}
+		if got := attributes[sempStatusKey].AsString(); got != tt.sempStatus {
+			t.Errorf("%v: got SEMP status %q, want %q", tt.name, got, tt.sempStatus)
+		}
+		if got := attributes[sempRetryCountKey].AsInt64(); got != tt.retries {
+			t.Errorf("%v: got %d retries, want %d", tt.name, got, tt.retries)
+		}
+		if got := attributes[sempRequestIDKey].AsString(); got != tt.requestID {
+			t.Errorf("%v: got request ID %q, want %q", tt.name, got, tt.requestID)
+		}
+		if _, ok := attributes[sempRateLimitKey]; !ok {
+			t.Errorf("%v: rate limit wait not recorded", tt.name)
+		}
+	}
+}
+
+func TestRequestSpanErrorsAreRedacted(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	const opaquePassword = "Opaque-Passw0rd"
+	client := NewClient(server.URL+"/SEMP/v2/config", false, false, BasicAuth("admin", "admin"), Retries(0, 0, 0))
+	client.AddSecrets(opaquePassword)
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/msgVpns/default?opaquePassword="+opaquePassword); err == nil {
+		t.Fatal("expected the connection to fail")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	texts := []string{spans[0].Status().Description}
+	for _, event := range spans[0].Events() {
+		for _, a := range event.Attributes {
+			texts = append(texts, a.Value.Emit())
+		}
+	}
+	if len(texts) < 2 {
+		t.Fatalf("expected the error to be recorded, got %q", texts)
+	}
+	for _, text := range texts {
+		if strings.Contains(text, opaquePassword) {
+			t.Errorf("span reveals the opaque password: %v", text)
+		}
+	}
+}
+
+func TestTraceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.json")
+	t.Setenv(TraceFileEnv, path)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	stopTracing, err := StartTracing(context.Background(), "test", "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(aboutApiHandler))
+	t.Cleanup(server.Close)
+	client := NewClient(server.URL+"/SEMP/v2/config", false, false, BasicAuth("admin", "admin"))
+	if _, err := client.RequestWithoutBody(context.Background(), http.MethodGet, "/about/api"); err != nil {
+		t.Fatal(err)
+	}
+	stopTracing(context.Background())
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var span struct {
+		Name string
+	}
+	if err := json.Unmarshal(content, &span); err != nil {
+		t.Fatalf("trace file is not a JSON span: %v\n%s", err, content)
+	}
+	if span.Name != "SEMP GET /about/api" {
+		t.Errorf("got span %q, want SEMP GET /about/api", span.Name)
+	}
+}
+
+func TestPathTemplateMatcher(t *testing.T) {
+	m := newPathTemplateMatcher([]string{
+		"/msgVpns/{msgVpnName}",
+		"/msgVpns/{msgVpnName}/queues/{queueName}",
+		"/msgVpns/{msgVpnName}/queues/{queueName}/subscriptions/{subscriptionTopic}",
+		"/msgVpns/{msgVpnName}/bridges/{bridgeName},{bridgeVirtualRouter}",
+		"/about/api",
+	})
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/msgVpns/default", "/msgVpns/{msgVpnName}"},
+		{"/msgVpns", "/msgVpns"},
+		{"/msgVpns/default/queues/q1", "/msgVpns/{msgVpnName}/queues/{queueName}"},
+		{"/msgVpns/default/queues", "/msgVpns/{msgVpnName}/queues"},
+		{"/msgVpns/default/queues/q1/subscriptions/a%2Fb", "/msgVpns/{msgVpnName}/queues/{queueName}/subscriptions/{subscriptionTopic}"},
+		{"/msgVpns/default/bridges/b1,auto", "/msgVpns/{msgVpnName}/bridges/{bridgeName},{bridgeVirtualRouter}"},
+		{"/about/api", "/about/api"},
+		{"/about/user", "/about/user"},
+	}
+	for _, tt := range tests {
+		if got := m.match(tt.path); got != tt.want {
+			t.Errorf("match(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
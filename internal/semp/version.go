@@ -0,0 +1,94 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// showVersionRequest is the SEMP v1 request of the broker version, which SEMP v2 does not report
+const showVersionRequest = "<rpc><show><version/></show></rpc>"
+
+type showVersionReply struct {
+	ExecuteResult struct {
+		Code   string `xml:"code,attr"`
+		Reason string `xml:"reason,attr"`
+	} `xml:"execute-result"`
+	CurrentLoad string `xml:"rpc>show>version>current-load"`
+}
+
+// BrokerVersion returns the firmware version of the broker, such as 10.6.1.52. It is read through the legacy SEMP v1
+// API, which may not be available to the user. The request does not change the broker, so it is also sent by
+// read-only clients.
+func (c *Client) BrokerVersion(ctx context.Context) (string, error) {
+	ctx = c.MaskLogs(ctx)
+	_, sempURL := c.activeURL()
+	u, err := url.Parse(sempURL)
+	if err != nil {
+		return "", err
+	}
+	// the SEMP v1 API is served at /SEMP of the broker, next to the SEMP v2 API
+	base, _, _ := strings.Cut(u.Path, "/SEMP/")
+	u.Path = strings.TrimSuffix(base, "/") + "/SEMP"
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(showVersionRequest))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "text/xml")
+	rawBody, _, err := c.doRequest(request)
+	if err != nil {
+		return "", c.redactor.redactError(withExecuteResult(err))
+	}
+	var reply showVersionReply
+	if err := xml.Unmarshal(rawBody, &reply); err != nil {
+		return "", fmt.Errorf("could not parse SEMP v1 version response: %w", err)
+	}
+	if reply.ExecuteResult.Code != "ok" {
+		return "", fmt.Errorf("SEMP v1 version request failed: %v %v", reply.ExecuteResult.Code, reply.ExecuteResult.Reason)
+	}
+	// the current load is the version prefixed with the product, for example soltr_10.6.1.52
+	brokerVersion := strings.TrimSpace(reply.CurrentLoad)
+	if i := strings.LastIndex(brokerVersion, "_"); i >= 0 {
+		brokerVersion = brokerVersion[i+1:]
+	}
+	if brokerVersion == "" {
+		return "", errors.New("SEMP v1 version response does not include the broker version")
+	}
+	return brokerVersion, nil
+}
+
+// withExecuteResult replaces the description of a SEMP v1 error response by the reason of its execute result, if the
+// response body holds one, keeping the HTTP status of the error
+func withExecuteResult(err error) error {
+	var sempErr *Error
+	if !errors.As(err, &sempErr) {
+		return err
+	}
+	var reply showVersionReply
+	if xml.Unmarshal([]byte(sempErr.Description), &reply) != nil || reply.ExecuteResult.Code == "" {
+		return err
+	}
+	withResult := *sempErr
+	withResult.Description = strings.TrimSpace(fmt.Sprintf("SEMP v1 request failed: %v %v", reply.ExecuteResult.Code, reply.ExecuteResult.Reason))
+	return &withResult
+}
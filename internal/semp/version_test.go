@@ -0,0 +1,71 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2024 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBrokerVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		reply      string
+		want       string
+		wantErr    bool
+		wantReason string
+	}{
+		{"Ok", http.StatusOK, `<rpc-reply semp-version="soltr/10_6"><rpc><show><version><description>Solace PubSub+ Standard Version 10.6.1.52</description><current-load>soltr_10.6.1.52</current-load></version></show></rpc><execute-result code="ok"/></rpc-reply>`, "10.6.1.52", false, ""},
+		{"Unauthorized", http.StatusOK, `<rpc-reply><execute-result code="fail" reason="permission denied"/></rpc-reply>`, "", true, "permission denied"},
+		{"NotXML", http.StatusOK, `{"meta":{"responseCode":404}}`, "", true, ""},
+		{"Forbidden", http.StatusForbidden, `<rpc-reply><execute-result code="fail" reason="Permission not allowed"/></rpc-reply>`, "", true, "Permission not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newTestBroker(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != http.MethodPost || r.URL.Path != "/SEMP" || string(body) != showVersionRequest || r.Header.Get("Content-Type") != "text/xml" {
+					t.Errorf("unexpected request %v %v %v %s", r.Method, r.URL.Path, r.Header.Get("Content-Type"), body)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.reply))
+			})
+			// the version is read in read-only mode as well
+			client := NewClient(broker.URL+"/SEMP/v2/config", true, false, BasicAuth("admin", "admin"), RequestLimits(time.Minute, 0), ReadOnly(true))
+			got, err := client.BrokerVersion(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BrokerVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BrokerVersion() = %v, want %v", got, tt.want)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantReason) {
+				t.Errorf("BrokerVersion() error = %v, want the reason %q", err, tt.wantReason)
+			}
+			var sempErr *Error
+			if tt.status != http.StatusOK && (!errors.As(err, &sempErr) || sempErr.HTTPStatus != tt.status || strings.Contains(sempErr.Description, "<rpc-reply>")) {
+				t.Errorf("BrokerVersion() error = %#v, want the HTTP status %v and the execute result", err, tt.status)
+			}
+		})
+	}
+}
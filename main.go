@@ -22,10 +22,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"terraform-provider-solacebroker/cmd"
 	"terraform-provider-solacebroker/internal/broker"
 	_ "terraform-provider-solacebroker/internal/broker/generated"
+	"terraform-provider-solacebroker/internal/semp"
 
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
 
@@ -41,12 +44,12 @@ var (
 )
 
 func main() {
-	if broker.SempDetail.Platform != expectedPlatform {
-		fmt.Printf("Provider error: wrong platform SEMP API spec \"%s\" used, expected \"%s\"\n", broker.SempDetail.Platform, expectedPlatform)
+	if _, ok := broker.SempDetails[expectedPlatform]; !ok {
+		fmt.Printf("Provider error: wrong platform SEMP API spec \"%s\" used, expected \"%s\"\n", strings.Join(broker.SupportedPlatforms(), ", "), expectedPlatform)
 		os.Exit(1)
 	}
 	broker.ProviderVersion = version
-	if len(os.Args) > 1 && (os.Args[1] == "generate" || os.Args[1] == "help" || os.Args[1] == "--help" || os.Args[1] == "-h" || os.Args[1] == "version") {
+	if len(os.Args) > 1 && (os.Args[1] == "generate" || os.Args[1] == "help" || os.Args[1] == "--help" || os.Args[1] == "-h" || os.Args[1] == "version" || os.Args[1] == "mock-broker") {
 		err := cmd.Execute()
 		if err != nil && err.Error() != "" {
 			fmt.Println(err)
@@ -67,7 +70,15 @@ func main() {
 		if debug {
 			go debugRun(os.Getenv("SOLACEBROKER_DEBUG_RUN"), opts.Address)
 		}
-		err := providerserver.Serve(context.Background(), broker.New(version), opts)
+		stopTracing, err := semp.StartTracing(context.Background(), "terraform-provider-solacebroker", version)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		// the provider instance is kept to end its broker sessions and report its statistics once Terraform stops it
+		brokerProvider := &broker.BrokerProvider{Version: version}
+		err = providerserver.Serve(context.Background(), func() provider.Provider { return brokerProvider }, opts)
+		brokerProvider.Close(context.Background())
+		stopTracing(context.Background())
 		if err != nil {
 			log.Fatal(err.Error())
 		}
@@ -0,0 +1,24 @@
+#!/bin/sh
+# Generates the Appliance variant of the broker object types into internal/broker/generated, next to the Software
+# Event Broker code generated from ci/swagger_spec. The provider registers both and selects the variant matching the
+# platform the broker reports at /about/api.
+#
+# Usage: scripts/generate-appliance-variant.sh, from the repository root, with broker-terraform-code-generator
+# installed in ~/go/bin. Does nothing if no Appliance SEMP spec is present in ci/swagger_spec_appliance.
+set -e
+
+BASE=$(pwd)
+SPEC=$(ls "$BASE"/ci/swagger_spec_appliance/*.json 2>/dev/null | head -n 1)
+if [ -z "$SPEC" ]; then
+  echo "No Appliance SEMP spec in ci/swagger_spec_appliance, skipping the Appliance variant"
+  exit 0
+fi
+echo "Generating the Appliance variant using spec $SPEC"
+OUT=$(mktemp -d)
+trap 'rm -rf "$OUT"' EXIT
+cd "$OUT"
+SEMP_V2_SWAGGER_CONFIG_EXTENDED_JSON="$SPEC" ~/go/bin/broker-terraform-code-generator appliance-provider all
+cd "$BASE"
+# the object types are registered as the Appliance variants of the Software Event Broker object types, the version
+# details after those of the Software Event Broker spec, which stays the default platform
+go run ./tools/appliancevariant "$OUT" internal/broker/generated
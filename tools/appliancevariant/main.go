@@ -0,0 +1,193 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2025 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command appliancevariant turns the code generated from the Appliance SEMP spec into the Appliance variant of the
+// object types generated from the Software Event Broker spec.
+//
+// Usage: go run ./tools/appliancevariant <generator output directory> <internal/broker/generated directory>
+//
+// Each object type file is written with an Appliance prefix and the Platform of its broker.EntityInputs set to
+// Appliance. VersionDetails.go is written as VersionDetailsAppliance.go with its constants renamed, so that the
+// Software Event Broker spec stays the default platform.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	platform           = "Appliance"
+	versionDetailsFile = "VersionDetails.go"
+)
+
+// versionConstants are the constants of VersionDetails.go, which would clash with those of the default platform
+var versionConstants = []string{"BasePath", "SempVersion", "Platform"}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: appliancevariant <generator output directory> <generated code directory>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(inputDir, outputDir string) error {
+	files, err := filepath.Glob(filepath.Join(inputDir, "*.go"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no generated code in %v", inputDir)
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(file)
+		var out []byte
+		if name == versionDetailsFile {
+			name = "VersionDetails" + platform + ".go"
+			out, err = renameVersionConstants(name, src)
+		} else {
+			name = platform + name
+			out, err = setPlatform(name, src)
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, name), out, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// edit inserts text at offset, replacing length bytes
+type edit struct {
+	offset int
+	length int
+	text   string
+}
+
+// apply applies the edits to src and formats the result
+func apply(name string, src []byte, edits []edit) ([]byte, error) {
+	slices.SortFunc(edits, func(a, b edit) int { return b.offset - a.offset })
+	out := bytes.Clone(src)
+	for _, e := range edits {
+		out = slices.Concat(out[:e.offset], []byte(e.text), out[e.offset+e.length:])
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	return formatted, nil
+}
+
+// setPlatform sets the Platform of each broker.EntityInputs literal, after its TerraformName
+func setPlatform(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var edits []edit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		literal, ok := n.(*ast.CompositeLit)
+		if !ok || !isEntityInputs(literal.Type) {
+			return true
+		}
+		terraformName := field(literal, "TerraformName")
+		if terraformName == nil || field(literal, "Platform") != nil {
+			err = fmt.Errorf("%v: %v: expected an EntityInputs literal with a TerraformName and without a Platform", name, fset.Position(literal.Pos()))
+			return false
+		}
+		// the field is added on the line after the TerraformName
+		end := fset.Position(terraformName.End()).Offset
+		newline := bytes.IndexByte(src[end:], '\n')
+		if newline < 0 {
+			err = fmt.Errorf("%v: %v: expected the EntityInputs fields on separate lines", name, fset.Position(literal.Pos()))
+			return false
+		}
+		edits = append(edits, edit{offset: end + newline + 1, text: fmt.Sprintf("Platform: %q,\n", platform)})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(edits) == 0 {
+		return nil, fmt.Errorf("%v: no EntityInputs literal", name)
+	}
+	return apply(name, src, edits)
+}
+
+// renameVersionConstants prefixes the version constants and their uses with the platform
+func renameVersionConstants(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var edits []edit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && slices.Contains(versionConstants, ident.Name) {
+			edits = append(edits, edit{offset: fset.Position(ident.Pos()).Offset, length: len(ident.Name), text: strings.ToLower(platform) + ident.Name})
+		}
+		// the selectors of other packages, such as broker.Platform, are not the constants
+		_, isSelector := n.(*ast.SelectorExpr)
+		return !isSelector
+	})
+	if len(edits) != 2*len(versionConstants) {
+		return nil, fmt.Errorf("%v: expected the declaration and one use of each of %v", name, strings.Join(versionConstants, ", "))
+	}
+	return apply(name, src, edits)
+}
+
+func isEntityInputs(expr ast.Expr) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "EntityInputs" {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && pkg.Name == "broker"
+}
+
+// field returns the key-value element of a struct literal with the given key
+func field(literal *ast.CompositeLit, key string) *ast.KeyValueExpr {
+	for _, elt := range literal.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == key {
+				return kv
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// terraform-provider-solacebroker
+//
+// Copyright 2025 Solace Corporation. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testObjectType = `package generated
+
+import "terraform-provider-solacebroker/internal/broker"
+
+func init() {
+	info := broker.EntityInputs{
+		TerraformName:       "msg_vpn_queue",
+		MarkdownDescription: "TerraformName: \"not a field\"",
+		Attributes: []*broker.AttributeInfo{
+			{
+				TerraformName: "queue_name",
+			},
+		},
+	}
+	broker.RegisterBrokerResource(info)
+}
+`
+
+const testVersionDetails = `package generated
+
+import "terraform-provider-solacebroker/internal/broker"
+
+const BasePath = "/SEMP/v2/config"
+const SempVersion = "2.49"
+const Platform = "Appliance"
+
+func init() {
+	broker.RegisterSempVersionDetails(BasePath, SempVersion, Platform)
+}
+`
+
+func TestRun(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	for name, src := range map[string]string{"MsgVpnQueue.go": testObjectType, "VersionDetails.go": testVersionDetails} {
+		if err := os.WriteFile(filepath.Join(in, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := run(in, out); err != nil {
+		t.Fatal(err)
+	}
+
+	objectType, err := os.ReadFile(filepath.Join(out, "ApplianceMsgVpnQueue.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\t\tTerraformName:       \"msg_vpn_queue\",\n\t\tPlatform:            \"Appliance\",\n"
+	if !strings.Contains(string(objectType), want) || strings.Count(string(objectType), "Platform") != 1 {
+		t.Errorf("expected only the EntityInputs to have the Appliance platform, got:\n%s", objectType)
+	}
+
+	versionDetails, err := os.ReadFile(filepath.Join(out, "VersionDetailsAppliance.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`const applianceBasePath = "/SEMP/v2/config"`,
+		`const appliancePlatform = "Appliance"`,
+		`broker.RegisterSempVersionDetails(applianceBasePath, applianceSempVersion, appliancePlatform)`,
+	} {
+		if !strings.Contains(string(versionDetails), want) {
+			t.Errorf("expected %q, got:\n%s", want, versionDetails)
+		}
+	}
+}
+
+func TestSetPlatformTwice(t *testing.T) {
+	once, err := setPlatform("MsgVpnQueue.go", []byte(testObjectType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := setPlatform("MsgVpnQueue.go", once); err == nil {
+		t.Error("expected an error for an EntityInputs literal that already has a platform")
+	}
+}